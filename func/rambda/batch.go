@@ -0,0 +1,62 @@
+package rambda
+
+import (
+	"runtime/debug"
+	"sort"
+	"sync"
+)
+
+// safeCall runs fn, turning a panic into a *PanicError so one bad item cannot take down a whole batch.
+func safeCall(fn func() error) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = &PanicError{Value: v, Stack: debug.Stack()}
+		}
+	}()
+	return fn()
+}
+
+// processBatch calls fn for every index in [0, n) using up to concurrency goroutines
+// and returns the indexes that failed in ascending order.
+func processBatch(n, concurrency int, fn func(i int) error) []int {
+	if concurrency > n {
+		concurrency = n
+	}
+	if concurrency <= 1 {
+		var failed []int
+		for i := 0; i < n; i++ {
+			if err := safeCall(func() error { return fn(i) }); err != nil {
+				failed = append(failed, i)
+			}
+		}
+		return failed
+	}
+
+	var (
+		mu     sync.Mutex
+		failed []int
+		wg     sync.WaitGroup
+	)
+	indexes := make(chan int)
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if err := safeCall(func() error { return fn(i) }); err != nil {
+					mu.Lock()
+					failed = append(failed, i)
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	sort.Ints(failed)
+	return failed
+}
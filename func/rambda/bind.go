@@ -0,0 +1,112 @@
+package rambda
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// BindRequest fills the fields of the struct pointed to by dst from req based on their tags:
+//
+//	path:"id"     a path parameter; a missing one is a validation failure
+//	query:"limit" a query string parameter
+//	header:"X-Api-Key" a header, matched case-insensitively
+//
+// Fields may be strings, bools, integers or floats, or pointers to them.
+// After binding, dst is checked with Validate and every problem is reported in one *ValidationError.
+func BindRequest(req *APIRequest, dst any) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("rambda: BindRequest requires a pointer to a struct, got %T", dst)
+	}
+	rv = rv.Elem()
+	rt := rv.Type()
+
+	var fields []FieldError
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		var (
+			name  string
+			value string
+			found bool
+		)
+		if name = sf.Tag.Get("path"); name != "" {
+			value, found = req.PathParameters[name]
+			if !found {
+				fields = append(fields, FieldError{Field: name, Rule: "required", Message: "path parameter is required"})
+				continue
+			}
+		} else if name = sf.Tag.Get("query"); name != "" {
+			value, found = req.QueryStringParameters[name]
+		} else if name = sf.Tag.Get("header"); name != "" {
+			value, found = header(req.Headers, name)
+		} else {
+			continue
+		}
+		if !found {
+			continue
+		}
+
+		if err := setField(rv.Field(i), value); err != nil {
+			fields = append(fields, FieldError{Field: name, Rule: "type", Message: err.Error()})
+		}
+	}
+
+	var ve *ValidationError
+	if err := Validate(dst); errors.As(err, &ve) {
+		fields = append(fields, ve.Fields...)
+	}
+	if len(fields) > 0 {
+		return &ValidationError{Fields: fields}
+	}
+	return nil
+}
+
+// setField parses value into fv according to its kind.
+func setField(fv reflect.Value, value string) error {
+	if fv.Kind() == reflect.Pointer {
+		elem := reflect.New(fv.Type().Elem())
+		if err := setField(elem.Elem(), value); err != nil {
+			return err
+		}
+		fv.Set(elem)
+		return nil
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("must be a boolean")
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, fv.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("must be an integer")
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, fv.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("must be a non-negative integer")
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, fv.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("must be a number")
+		}
+		fv.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported field type %s", fv.Type())
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package rambda
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+type listItemsParams struct {
+	UserID  string `path:"userId"`
+	Limit   int    `query:"limit" validate:"max=100"`
+	Verbose *bool  `query:"verbose"`
+	APIKey  string `header:"X-Api-Key" validate:"required"`
+	Ignored string
+}
+
+func TestBindRequest(t *testing.T) {
+	req := &APIRequest{APIGatewayV2HTTPRequest: events.APIGatewayV2HTTPRequest{
+		PathParameters:        map[string]string{"userId": "u1"},
+		QueryStringParameters: map[string]string{"limit": "20", "verbose": "true"},
+		Headers:               map[string]string{"x-api-key": "secret"},
+	}}
+
+	var params listItemsParams
+	if err := BindRequest(req, &params); err != nil {
+		t.Fatal(err)
+	}
+	if params.UserID != "u1" || params.Limit != 20 || params.Verbose == nil || !*params.Verbose || params.APIKey != "secret" {
+		t.Errorf("params = %+v", params)
+	}
+}
+
+func TestBindRequestErrors(t *testing.T) {
+	req := &APIRequest{APIGatewayV2HTTPRequest: events.APIGatewayV2HTTPRequest{
+		QueryStringParameters: map[string]string{"limit": "many"},
+	}}
+
+	var params listItemsParams
+	err := BindRequest(req, &params)
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("error = %v, want *ValidationError", err)
+	}
+	var got []string
+	for _, f := range ve.Fields {
+		got = append(got, f.Field+"/"+f.Rule)
+	}
+	if want := []string{"userId/required", "limit/type", "X-Api-Key/required"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("fields = %v, want %v", got, want)
+	}
+}
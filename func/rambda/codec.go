@@ -0,0 +1,197 @@
+package rambda
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"sync"
+)
+
+// Codec encodes responses and decodes events for every handler in this package.
+// Set one with SetCodec to replace encoding/json, for example with a faster implementation.
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+var (
+	codecMu     sync.RWMutex
+	marshalFn   func(any) ([]byte, error)
+	unmarshalFn func([]byte, any) error
+)
+
+// SetCodec routes all event decoding and response encoding through c.
+// Passing nil restores the encoding/json based default.
+func SetCodec(c Codec) {
+	codecMu.Lock()
+	defer codecMu.Unlock()
+	if c == nil {
+		marshalFn, unmarshalFn = nil, nil
+		return
+	}
+	marshalFn, unmarshalFn = c.Marshal, c.Unmarshal
+}
+
+// JSONCodec builds an encoding/json based Codec from the same options as JSONMarshaler and JSONUnmarshaler.
+func JSONCodec(opts ...Option) Codec {
+	return funcCodec{
+		marshal:   JSONMarshaler(opts...),
+		unmarshal: JSONUnmarshaler(opts...),
+	}
+}
+
+type funcCodec struct {
+	marshal   func(any) ([]byte, error)
+	unmarshal func([]byte, any) error
+}
+
+func (c funcCodec) Marshal(v any) ([]byte, error)      { return c.marshal(v) }
+func (c funcCodec) Unmarshal(data []byte, v any) error { return c.unmarshal(data, v) }
+
+// SetMarshaler replaces the encoding half of the Codec: the function used to encode
+// APIResponse bodies built with JSON and the responses returned to Lambda.
+// Passing nil restores the default behavior, which is json.Marshal for bodies and,
+// as with lambda.Start, encoding without HTML escaping for Lambda responses.
+func SetMarshaler(fn func(any) ([]byte, error)) {
+	codecMu.Lock()
+	defer codecMu.Unlock()
+	marshalFn = fn
+}
+
+// SetUnmarshaler replaces the decoding half of the Codec, used for incoming events.
+// Passing nil restores json.Unmarshal.
+func SetUnmarshaler(fn func([]byte, any) error) {
+	codecMu.Lock()
+	defer codecMu.Unlock()
+	unmarshalFn = fn
+}
+
+// JSONMarshaler builds an encoding/json based marshaler for SetMarshaler.
+// It escapes HTML unless WithEscapeHTML(false) is given, and with WithSortedKeys it
+// produces canonical output.
+func JSONMarshaler(opts ...Option) func(any) ([]byte, error) {
+	o := newOptions(append([]Option{WithEscapeHTML(true)}, opts...))
+	return func(v any) ([]byte, error) {
+		b, err := encodeJSON(v, o.escapeHTML)
+		if err != nil || !o.sortedKeys {
+			return b, err
+		}
+		return canonicalJSON(b, o.escapeHTML)
+	}
+}
+
+// JSONUnmarshaler builds an encoding/json based unmarshaler for SetUnmarshaler.
+// WithUseNumber keeps numbers as json.Number.
+func JSONUnmarshaler(opts ...Option) func([]byte, any) error {
+	o := newOptions(opts)
+	return func(b []byte, v any) error {
+		dec := json.NewDecoder(bytes.NewReader(b))
+		if o.useNumber {
+			dec.UseNumber()
+		}
+		return dec.Decode(v)
+	}
+}
+
+func encodeJSON(v any, escapeHTML bool) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(escapeHTML)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	// Encoder は末尾に改行を付けるので取り除く
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// canonicalJSON rewrites b with the keys of every object sorted, struct fields included.
+// Arrays keep their order and numbers their exact text.
+func canonicalJSON(b []byte, escapeHTML bool) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := writeCanonical(&buf, v, escapeHTML); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeCanonical(buf *bytes.Buffer, v any, escapeHTML bool) error {
+	switch v := v.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		buf.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonical(buf, k, escapeHTML); err != nil {
+				return err
+			}
+			buf.WriteByte(':')
+			if err := writeCanonical(buf, v[k], escapeHTML); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case []any:
+		buf.WriteByte('[')
+		for i, item := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonical(buf, item, escapeHTML); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	default:
+		b, err := encodeJSON(v, escapeHTML)
+		if err != nil {
+			return err
+		}
+		buf.Write(b)
+	}
+	return nil
+}
+
+// marshal encodes values embedded in responses, such as APIResponse bodies.
+func marshal(v any) ([]byte, error) {
+	codecMu.RLock()
+	fn := marshalFn
+	codecMu.RUnlock()
+	if fn == nil {
+		return json.Marshal(v)
+	}
+	return fn(v)
+}
+
+// encodeResponse encodes the value returned to Lambda.
+func encodeResponse(v any) ([]byte, error) {
+	codecMu.RLock()
+	fn := marshalFn
+	codecMu.RUnlock()
+	if fn == nil {
+		return encodeJSON(v, false)
+	}
+	return fn(v)
+}
+
+// unmarshal decodes incoming events.
+func unmarshal(b []byte, v any) error {
+	codecMu.RLock()
+	fn := unmarshalFn
+	codecMu.RUnlock()
+	if fn == nil {
+		return json.Unmarshal(b, v)
+	}
+	return fn(b, v)
+}
@@ -0,0 +1,128 @@
+package rambda
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestDefaultEncodingUnchanged(t *testing.T) {
+	res := JSON(http.StatusOK, map[string]string{"html": "<b>"})
+	if res.Body != `{"html":"\u003cb\u003e"}` {
+		t.Errorf("JSON body = %s", res.Body)
+	}
+
+	h := Handler(func(ctx context.Context, event json.RawMessage) (any, error) {
+		return map[string]string{"html": "<b>"}, nil
+	})
+	b, err := h.Invoke(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// lambda.Start と同じく HTML はエスケープしない
+	if string(b) != `{"html":"<b>"}` {
+		t.Errorf("Invoke() = %s", b)
+	}
+}
+
+func TestSetMarshaler(t *testing.T) {
+	SetMarshaler(JSONMarshaler(WithEscapeHTML(false)))
+	t.Cleanup(func() { SetMarshaler(nil) })
+
+	res := JSON(http.StatusOK, map[string]string{"html": "<b>"})
+	if res.Body != `{"html":"<b>"}` {
+		t.Errorf("JSON body = %s", res.Body)
+	}
+}
+
+func TestSetUnmarshalerUseNumber(t *testing.T) {
+	SetUnmarshaler(JSONUnmarshaler(WithUseNumber()))
+	t.Cleanup(func() { SetUnmarshaler(nil) })
+
+	h := Typed(func(ctx context.Context, in map[string]any) (any, error) {
+		return in["id"], nil
+	})
+	res, err := h(context.Background(), json.RawMessage(`{"id":12345678901234567890}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, ok := res.(json.Number); !ok || n.String() != "12345678901234567890" {
+		t.Errorf("id = %#v, want json.Number", res)
+	}
+}
+
+type noopCodec struct{}
+
+func (noopCodec) Marshal(v any) ([]byte, error)      { return []byte(`{}`), nil }
+func (noopCodec) Unmarshal(data []byte, v any) error { return nil }
+
+func TestSetCodec(t *testing.T) {
+	SetCodec(noopCodec{})
+	t.Cleanup(func() { SetCodec(nil) })
+
+	h := Typed(func(ctx context.Context, in greetRequest) (greetResponse, error) {
+		if in.Name != "" {
+			t.Errorf("event was decoded by encoding/json: %+v", in)
+		}
+		return greetResponse{Message: "hi"}, nil
+	})
+	b, err := h.Invoke(context.Background(), []byte(`{"name":"rambda"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{}` {
+		t.Errorf("Invoke() = %s, want the codec output", b)
+	}
+}
+
+func TestWithSortedKeys(t *testing.T) {
+	SetCodec(JSONCodec(WithSortedKeys(), WithEscapeHTML(false)))
+	t.Cleanup(func() { SetCodec(nil) })
+
+	type item struct {
+		Zeta  int    `json:"zeta"`
+		Alpha string `json:"alpha"`
+	}
+	h := Handler(func(ctx context.Context, event json.RawMessage) (any, error) {
+		return Response{
+			Message: "<ok>",
+			Input: []any{
+				item{Zeta: 1, Alpha: "a"},
+				map[string]any{"b": json.Number("12345678901234567890"), "a": []int{3, 1, 2}},
+			},
+		}, nil
+	})
+	b, err := h.Invoke(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"input":[{"alpha":"a","zeta":1},{"a":[3,1,2],"b":12345678901234567890}],"message":"<ok>"}`
+	if string(b) != want {
+		t.Errorf("Invoke() = %s, want %s", b, want)
+	}
+}
+
+func BenchmarkCodec(b *testing.B) {
+	payload := []byte(`{"name":"rambda"}`)
+	h := Typed(greet)
+
+	for _, bm := range []struct {
+		name  string
+		codec Codec
+	}{
+		{"stdlib", nil},
+		{"noop", noopCodec{}},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			SetCodec(bm.codec)
+			defer SetCodec(nil)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := h.Invoke(context.Background(), payload); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package rambda
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+)
+
+var (
+	coldStartOnce sync.Once
+	warm          atomic.Bool
+)
+
+// IsColdStart reports whether the current invocation is the first one served by this container.
+// Invocations are counted by Handler.Invoke, which Start, Router and Invoke go through,
+// and by ColdStartMiddleware and WarmupMiddleware for handlers called without it.
+func IsColdStart() bool {
+	return !warm.Load()
+}
+
+// markInvocation records the start of an invocation and reports whether it is the container's first.
+func markInvocation() bool {
+	cold := false
+	coldStartOnce.Do(func() { cold = true })
+	if !cold {
+		warm.Store(true)
+	}
+	return cold
+}
+
+// recordInvocation counts the invocation of ctx once, however many layers call it,
+// and reports whether it is the container's first.
+func recordInvocation(ctx context.Context) bool {
+	state := invocationFromContext(ctx)
+	if state == nil {
+		return markInvocation()
+	}
+	if !state.recorded {
+		state.recorded = true
+		state.cold = markInvocation()
+	}
+	return state.cold
+}
+
+// ColdStartMiddleware emits a ColdStart EMF metric in namespace on every invocation:
+// 1 for the first invocation of the container and 0 afterwards.
+func ColdStartMiddleware(namespace string) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, event json.RawMessage) (any, error) {
+			value := 0.0
+			if recordInvocation(ctx) {
+				value = 1
+			}
+			dimensions := map[string]string{}
+			if lambdacontext.FunctionName != "" {
+				dimensions["FunctionName"] = lambdacontext.FunctionName
+			}
+			// メトリクスの出力に失敗しても呼び出し自体は続ける
+			_ = writeEMF(namespace, dimensions, []emfValue{{Name: "ColdStart", Unit: "Count", Value: value}})
+			return next(ctx, event)
+		}
+	}
+}
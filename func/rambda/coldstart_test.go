@@ -0,0 +1,105 @@
+package rambda
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// captureEMF redirects EMF output to a buffer for the duration of the test.
+func captureEMF(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	prev := emfOutput
+	emfOutput = &buf
+	t.Cleanup(func() { emfOutput = prev })
+	return &buf
+}
+
+func emfLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var lines []map[string]any
+	scanner := bufio.NewScanner(buf)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		var doc map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &doc); err != nil {
+			t.Fatalf("EMF line is not JSON: %s", scanner.Text())
+		}
+		lines = append(lines, doc)
+	}
+	return lines
+}
+
+func resetColdStart() {
+	coldStartOnce = sync.Once{}
+	warm.Store(false)
+}
+
+func TestColdStartMiddleware(t *testing.T) {
+	resetColdStart()
+	t.Cleanup(resetColdStart)
+	buf := captureEMF(t)
+
+	var cold []bool
+	h := Chain(func(ctx context.Context, event json.RawMessage) (any, error) {
+		cold = append(cold, IsColdStart())
+		return nil, nil
+	}, ColdStartMiddleware("rambda"))
+
+	for i := 0; i < 3; i++ {
+		if _, err := h(context.Background(), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if cold[0] != true || cold[1] != false || cold[2] != false {
+		t.Errorf("IsColdStart() = %v, want [true false false]", cold)
+	}
+	lines := emfLines(t, buf)
+	if len(lines) != 3 {
+		t.Fatalf("got %d EMF lines, want 3", len(lines))
+	}
+	for i, want := range []float64{1, 0, 0} {
+		if lines[i]["ColdStart"] != want {
+			t.Errorf("line %d ColdStart = %v, want %v", i, lines[i]["ColdStart"], want)
+		}
+		directives := lines[i]["_aws"].(map[string]any)["CloudWatchMetrics"].([]any)
+		if ns := directives[0].(map[string]any)["Namespace"]; ns != "rambda" {
+			t.Errorf("Namespace = %v", ns)
+		}
+	}
+}
+
+func TestIsColdStartWithoutMiddleware(t *testing.T) {
+	resetColdStart()
+	t.Cleanup(resetColdStart)
+	captureEMF(t)
+
+	var cold []bool
+	h := Chain(func(ctx context.Context, event json.RawMessage) (any, error) {
+		cold = append(cold, IsColdStart())
+		return nil, nil
+	}, ColdStartMiddleware("rambda"), WarmupMiddleware(nil))
+	plain := Handler(func(ctx context.Context, event json.RawMessage) (any, error) {
+		cold = append(cold, IsColdStart())
+		return nil, nil
+	})
+
+	// 1 回の呼び出しで複数の層が記録しても重複して数えない
+	if _, err := h.Invoke(context.Background(), []byte(`{}`)); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := Invoke(plain, []byte(`{}`)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if want := []bool{true, false, false}; !reflect.DeepEqual(cold, want) {
+		t.Errorf("IsColdStart() = %v, want %v", cold, want)
+	}
+}
@@ -0,0 +1,78 @@
+package rambda
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// requestHeaders holds the headers of an API Gateway event.
+type requestHeaders struct {
+	Headers map[string]string `json:"headers"`
+}
+
+// CompressionMiddleware gzips APIResponse bodies larger than the threshold set with
+// WithCompressionThreshold when the request's Accept-Encoding allows it.
+// Compressed bodies are base64 encoded with isBase64Encoded set, as API Gateway requires for binary data.
+func CompressionMiddleware(opts ...Option) Middleware {
+	o := newOptions(opts)
+	return func(next Handler) Handler {
+		return func(ctx context.Context, event json.RawMessage) (any, error) {
+			res, err := next(ctx, event)
+			if err != nil {
+				return res, err
+			}
+			apiRes, ok := asAPIResponse(res)
+			if !ok || apiRes.IsBase64Encoded || len(apiRes.Body) <= o.compressionThreshold {
+				return res, nil
+			}
+			if _, encoded := header(apiRes.Headers, "Content-Encoding"); encoded {
+				return res, nil
+			}
+
+			var req requestHeaders
+			if err := unmarshal(event, &req); err != nil {
+				return res, nil
+			}
+			if accept, _ := header(req.Headers, "Accept-Encoding"); !acceptsGzip(accept) {
+				return res, nil
+			}
+
+			var buf bytes.Buffer
+			zw := gzip.NewWriter(&buf)
+			if _, err := zw.Write([]byte(apiRes.Body)); err != nil {
+				return res, nil
+			}
+			if err := zw.Close(); err != nil {
+				return res, nil
+			}
+
+			apiRes = apiRes.withHeader("Content-Encoding", "gzip").withHeader("Vary", "Accept-Encoding")
+			apiRes.Body = base64.StdEncoding.EncodeToString(buf.Bytes())
+			apiRes.IsBase64Encoded = true
+			return apiRes, nil
+		}
+	}
+}
+
+// acceptsGzip reports whether an Accept-Encoding header value allows gzip.
+func acceptsGzip(accept string) bool {
+	for _, part := range strings.Split(accept, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding != "gzip" && coding != "*" {
+			continue
+		}
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if v, err := strconv.ParseFloat(q, 64); err == nil && v == 0 {
+				continue
+			}
+		}
+		return true
+	}
+	return false
+}
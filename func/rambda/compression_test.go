@@ -0,0 +1,65 @@
+package rambda
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestCompressionMiddleware(t *testing.T) {
+	large := strings.Repeat("a", 2048)
+	h := Chain(func(ctx context.Context, event json.RawMessage) (any, error) {
+		var req requestHeaders
+		_ = json.Unmarshal(event, &req)
+		if req.Headers["x-size"] == "small" {
+			return Text(http.StatusOK, "tiny"), nil
+		}
+		return Text(http.StatusOK, large), nil
+	}, CompressionMiddleware())
+
+	res, err := h(context.Background(), json.RawMessage(`{"headers":{"accept-encoding":"br, gzip;q=0.8"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	apiRes := res.(APIResponse)
+	if !apiRes.IsBase64Encoded || apiRes.Headers["Content-Encoding"] != "gzip" {
+		t.Fatalf("response not compressed: %+v", apiRes.Headers)
+	}
+	raw, _ := base64.StdEncoding.DecodeString(apiRes.Body)
+	zr, err := gzip.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(zr)
+	if string(body) != large {
+		t.Error("decompressed body does not match")
+	}
+
+	for _, payload := range []string{
+		`{"headers":{"accept-encoding":"gzip","x-size":"small"}}`,
+		`{"headers":{"accept-encoding":"gzip;q=0"}}`,
+		`{"headers":{}}`,
+	} {
+		res, _ := h(context.Background(), json.RawMessage(payload))
+		if res.(APIResponse).IsBase64Encoded {
+			t.Errorf("%s: response should not be compressed", payload)
+		}
+	}
+}
+
+func TestCompressionThreshold(t *testing.T) {
+	h := Chain(func(ctx context.Context, event json.RawMessage) (any, error) {
+		return Text(http.StatusOK, "0123456789"), nil
+	}, CompressionMiddleware(WithCompressionThreshold(5)))
+
+	res, _ := h(context.Background(), json.RawMessage(`{"headers":{"Accept-Encoding":"gzip"}}`))
+	if !res.(APIResponse).IsBase64Encoded {
+		t.Error("expected body above threshold to be compressed")
+	}
+}
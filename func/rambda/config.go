@@ -0,0 +1,113 @@
+package rambda
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// ConfigError lists every problem found by LoadConfig.
+type ConfigError struct {
+	// Missing holds the names of required variables that are not set.
+	Missing []string
+	// Invalid holds a message for each variable whose value could not be parsed.
+	Invalid []string
+}
+
+func (e *ConfigError) Error() string {
+	var parts []string
+	if len(e.Missing) > 0 {
+		parts = append(parts, "missing required environment variables: "+strings.Join(e.Missing, ", "))
+	}
+	if len(e.Invalid) > 0 {
+		parts = append(parts, "invalid environment variables: "+strings.Join(e.Invalid, "; "))
+	}
+	return "rambda: " + strings.Join(parts, "; ")
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// LoadConfig fills the struct pointed to by cfg from environment variables:
+//
+//	TableName string        `env:"TABLE_NAME,required"`
+//	Timeout   time.Duration `env:"TIMEOUT" default:"5s"`
+//	Origins   []string      `env:"ALLOWED_ORIGINS"`
+//
+// Fields may be strings, bools, integers, floats, time.Durations or comma separated []strings.
+// Nested structs without an env tag are loaded recursively. Every missing required
+// variable and unparsable value is reported at once in a *ConfigError, so a bad deploy
+// fails with the complete list.
+func LoadConfig(cfg any) error {
+	rv := reflect.ValueOf(cfg)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("rambda: LoadConfig requires a pointer to a struct, got %T", cfg)
+	}
+
+	var cerr ConfigError
+	loadStruct(rv.Elem(), &cerr)
+	if len(cerr.Missing) > 0 || len(cerr.Invalid) > 0 {
+		return &cerr
+	}
+	return nil
+}
+
+func loadStruct(rv reflect.Value, cerr *ConfigError) {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		fv := rv.Field(i)
+
+		tag, ok := sf.Tag.Lookup("env")
+		if !ok {
+			if fv.Kind() == reflect.Struct && fv.Type() != durationType {
+				loadStruct(fv, cerr)
+			}
+			continue
+		}
+		name, flags, _ := strings.Cut(tag, ",")
+		required := flags == "required"
+
+		value, found := os.LookupEnv(name)
+		if !found || value == "" {
+			if def, ok := sf.Tag.Lookup("default"); ok {
+				value = def
+			} else if required {
+				cerr.Missing = append(cerr.Missing, name)
+				continue
+			} else {
+				continue
+			}
+		}
+
+		if err := setConfigField(fv, value); err != nil {
+			cerr.Invalid = append(cerr.Invalid, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+}
+
+func setConfigField(fv reflect.Value, value string) error {
+	switch {
+	case fv.Type() == durationType:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("must be a duration")
+		}
+		fv.SetInt(int64(d))
+	case fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() == reflect.String:
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		fv.Set(reflect.ValueOf(items).Convert(fv.Type()))
+	default:
+		return setField(fv, value)
+	}
+	return nil
+}
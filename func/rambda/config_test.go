@@ -0,0 +1,58 @@
+package rambda
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testConfig struct {
+	TableName string        `env:"RAMBDA_TEST_TABLE,required"`
+	Region    string        `env:"RAMBDA_TEST_REGION" default:"us-east-1"`
+	Workers   int           `env:"RAMBDA_TEST_WORKERS"`
+	Debug     bool          `env:"RAMBDA_TEST_DEBUG"`
+	Timeout   time.Duration `env:"RAMBDA_TEST_TIMEOUT" default:"5s"`
+	Origins   []string      `env:"RAMBDA_TEST_ORIGINS"`
+	Queue     struct {
+		URL string `env:"RAMBDA_TEST_QUEUE_URL,required"`
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Setenv("RAMBDA_TEST_TABLE", "orders")
+	t.Setenv("RAMBDA_TEST_WORKERS", "4")
+	t.Setenv("RAMBDA_TEST_DEBUG", "true")
+	t.Setenv("RAMBDA_TEST_ORIGINS", "https://a.example.com, https://b.example.com")
+	t.Setenv("RAMBDA_TEST_QUEUE_URL", "https://sqs/queue")
+
+	var cfg testConfig
+	if err := LoadConfig(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.TableName != "orders" || cfg.Region != "us-east-1" || cfg.Workers != 4 || !cfg.Debug ||
+		cfg.Timeout != 5*time.Second || cfg.Queue.URL != "https://sqs/queue" {
+		t.Errorf("cfg = %+v", cfg)
+	}
+	if want := []string{"https://a.example.com", "https://b.example.com"}; !reflect.DeepEqual(cfg.Origins, want) {
+		t.Errorf("Origins = %v", cfg.Origins)
+	}
+}
+
+func TestLoadConfigReportsEveryProblem(t *testing.T) {
+	t.Setenv("RAMBDA_TEST_WORKERS", "many")
+	t.Setenv("RAMBDA_TEST_TIMEOUT", "soon")
+
+	var cfg testConfig
+	err := LoadConfig(&cfg)
+	var cerr *ConfigError
+	if !errors.As(err, &cerr) {
+		t.Fatalf("error = %v, want *ConfigError", err)
+	}
+	if want := []string{"RAMBDA_TEST_TABLE", "RAMBDA_TEST_QUEUE_URL"}; !reflect.DeepEqual(cerr.Missing, want) {
+		t.Errorf("Missing = %v, want %v", cerr.Missing, want)
+	}
+	if len(cerr.Invalid) != 2 {
+		t.Errorf("Invalid = %v, want 2 entries", cerr.Invalid)
+	}
+}
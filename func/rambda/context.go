@@ -0,0 +1,55 @@
+package rambda
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+)
+
+// Context is a read-only view of the invocation metadata Lambda attaches to the context.
+type Context struct {
+	requestID     string
+	functionName  string
+	memoryLimitMB int
+	deadline      time.Time
+}
+
+// FromContext extracts the invocation metadata from ctx.
+// When ctx carries no Lambda metadata, as in local tests, it returns a zero Context and false.
+func FromContext(ctx context.Context) (Context, bool) {
+	lc, ok := lambdacontext.FromContext(ctx)
+	if !ok {
+		return Context{}, false
+	}
+	c := Context{
+		requestID:     lc.AwsRequestID,
+		functionName:  lambdacontext.FunctionName,
+		memoryLimitMB: lambdacontext.MemoryLimitInMB,
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		c.deadline = deadline
+	}
+	return c, true
+}
+
+// RequestID returns the AWS request ID of the invocation.
+func (c Context) RequestID() string { return c.requestID }
+
+// FunctionName returns the name of the Lambda function.
+func (c Context) FunctionName() string { return c.functionName }
+
+// MemoryLimitMB returns the memory configured for the function.
+func (c Context) MemoryLimitMB() int { return c.memoryLimitMB }
+
+// Deadline returns when the invocation times out, or the zero time if unknown.
+func (c Context) Deadline() time.Time { return c.deadline }
+
+// RemainingTime returns the time left until the deadline, computed at each call.
+// It returns 0 once the deadline has passed or when it is unknown.
+func (c Context) RemainingTime() time.Duration {
+	if c.deadline.IsZero() {
+		return 0
+	}
+	return max(time.Until(c.deadline), 0)
+}
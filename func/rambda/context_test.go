@@ -0,0 +1,41 @@
+package rambda
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+)
+
+func TestFromContext(t *testing.T) {
+	ctx := lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{AwsRequestID: "req-1"})
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	lc, ok := FromContext(ctx)
+	if !ok {
+		t.Fatal("FromContext() ok = false")
+	}
+	if lc.RequestID() != "req-1" {
+		t.Errorf("RequestID() = %q", lc.RequestID())
+	}
+	first := lc.RemainingTime()
+	if first <= 0 || first > time.Minute {
+		t.Errorf("RemainingTime() = %s", first)
+	}
+	time.Sleep(time.Millisecond)
+	if lc.RemainingTime() >= first {
+		t.Error("RemainingTime() did not decrease")
+	}
+}
+
+func TestFromContextWithoutLambda(t *testing.T) {
+	lc, ok := FromContext(context.Background())
+	if ok {
+		t.Error("FromContext() ok = true")
+	}
+	if lc.RequestID() != "" || !lc.Deadline().IsZero() || lc.RemainingTime() != 0 {
+		t.Errorf("expected zero Context, got %+v", lc)
+	}
+}
@@ -0,0 +1,101 @@
+package rambda
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+// DefaultCorrelationKey is the header and message attribute name used when
+// CorrelationIDMiddleware is given an empty key.
+const DefaultCorrelationKey = "X-Correlation-Id"
+
+type correlationKey struct{}
+
+type correlation struct {
+	id  string
+	key string
+}
+
+// WithCorrelationID returns a copy of ctx carrying the correlation ID id.
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	c := correlation{id: id, key: DefaultCorrelationKey}
+	if prev, ok := ctx.Value(correlationKey{}).(correlation); ok {
+		c.key = prev.key
+	}
+	return context.WithValue(ctx, correlationKey{}, c)
+}
+
+// CorrelationIDFromContext returns the correlation ID carried by ctx, or "" if there is none.
+func CorrelationIDFromContext(ctx context.Context) string {
+	c, _ := ctx.Value(correlationKey{}).(correlation)
+	return c.id
+}
+
+// correlationProbe holds the places a correlation ID can arrive in.
+type correlationProbe struct {
+	Headers map[string]string `json:"headers"`
+	Records []struct {
+		MessageAttributes map[string]struct {
+			StringValue *string `json:"stringValue"`
+		} `json:"messageAttributes"`
+	} `json:"Records"`
+}
+
+// CorrelationIDMiddleware puts a correlation ID into the context of the inner handler.
+//
+// The ID is read from the API Gateway header key (matched case-insensitively) or from
+// the SQS message attribute key of the first record that has one; key defaults to
+// DefaultCorrelationKey. When neither is present a new UUID is generated.
+// LoggingMiddleware chained inside it logs the ID and StampCorrelationID forwards it.
+func CorrelationIDMiddleware(key string) Middleware {
+	if key == "" {
+		key = DefaultCorrelationKey
+	}
+	return func(next Handler) Handler {
+		return func(ctx context.Context, event json.RawMessage) (any, error) {
+			id := extractCorrelationID(event, key)
+			if id == "" {
+				id = newUUID()
+			}
+			ctx = context.WithValue(ctx, correlationKey{}, correlation{id: id, key: key})
+			return next(ctx, event)
+		}
+	}
+}
+
+func extractCorrelationID(event json.RawMessage, key string) string {
+	var probe correlationProbe
+	if err := unmarshal(event, &probe); err != nil {
+		return ""
+	}
+	if v, ok := header(probe.Headers, key); ok && v != "" {
+		return v
+	}
+	for _, r := range probe.Records {
+		if attr, ok := r.MessageAttributes[key]; ok && aws.ToString(attr.StringValue) != "" {
+			return *attr.StringValue
+		}
+	}
+	return ""
+}
+
+// StampCorrelationID adds the correlation ID of ctx to the attributes of an outgoing SQS
+// message, under the key CorrelationIDMiddleware read it from. attrs may be nil; the
+// possibly new map is returned. Without a correlation ID attrs is returned unchanged.
+func StampCorrelationID(ctx context.Context, attrs map[string]types.MessageAttributeValue) map[string]types.MessageAttributeValue {
+	c, ok := ctx.Value(correlationKey{}).(correlation)
+	if !ok || c.id == "" {
+		return attrs
+	}
+	if attrs == nil {
+		attrs = make(map[string]types.MessageAttributeValue, 1)
+	}
+	attrs[c.key] = types.MessageAttributeValue{
+		DataType:    aws.String("String"),
+		StringValue: aws.String(c.id),
+	}
+	return attrs
+}
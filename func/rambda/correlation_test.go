@@ -0,0 +1,69 @@
+package rambda
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+func TestCorrelationIDMiddleware(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		payload string
+		want    string
+	}{
+		{"api header", "", `{"headers":{"x-correlation-id":"abc"}}`, "abc"},
+		{"sqs attribute", "trace", `{"Records":[{"messageAttributes":{}},{"messageAttributes":{"trace":{"stringValue":"def","dataType":"String"}}}]}`, "def"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			h := Chain(func(ctx context.Context, event json.RawMessage) (any, error) {
+				got = CorrelationIDFromContext(ctx)
+				return nil, nil
+			}, CorrelationIDMiddleware(tt.key))
+			if _, err := h(context.Background(), json.RawMessage(tt.payload)); err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("correlation ID = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCorrelationIDGeneratedAndLogged(t *testing.T) {
+	var buf bytes.Buffer
+	var id string
+	h := Chain(func(ctx context.Context, event json.RawMessage) (any, error) {
+		id = CorrelationIDFromContext(ctx)
+		return nil, nil
+	}, CorrelationIDMiddleware(""), LoggingMiddleware(slog.New(slog.NewJSONHandler(&buf, nil))))
+
+	if _, err := h(context.Background(), json.RawMessage(`{}`)); err != nil {
+		t.Fatal(err)
+	}
+	if len(id) != 36 {
+		t.Fatalf("generated ID = %q, want a UUID", id)
+	}
+	if !strings.Contains(buf.String(), `"correlation_id":"`+id+`"`) {
+		t.Errorf("logs do not include the correlation ID: %s", buf.String())
+	}
+}
+
+func TestStampCorrelationID(t *testing.T) {
+	if attrs := StampCorrelationID(context.Background(), nil); attrs != nil {
+		t.Errorf("attrs = %v, want nil without a correlation ID", attrs)
+	}
+
+	attrs := StampCorrelationID(WithCorrelationID(context.Background(), "abc"), nil)
+	if got := aws.ToString(attrs[DefaultCorrelationKey].StringValue); got != "abc" {
+		t.Errorf("stamped ID = %q, want abc", got)
+	}
+}
@@ -0,0 +1,116 @@
+package rambda
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// CORSOptions configures CORSMiddleware.
+type CORSOptions struct {
+	// AllowedOrigins lists the origins allowed to call the API.
+	// An entry may contain one "*" wildcard, as in "https://*.example.com", and "*" alone allows any origin.
+	AllowedOrigins []string
+	// AllowedMethods is sent in preflight responses. It defaults to the common REST methods.
+	AllowedMethods []string
+	// AllowedHeaders is sent in preflight responses.
+	// When empty, the headers requested by the browser are echoed back.
+	AllowedHeaders []string
+	// AllowCredentials sets Access-Control-Allow-Credentials.
+	AllowCredentials bool
+	// MaxAge is how long, in seconds, browsers may cache a preflight response.
+	MaxAge int
+}
+
+var defaultCORSMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+	http.MethodPatch, http.MethodDelete,
+}
+
+// corsRequest holds the fields of an API Gateway event CORS needs, for both payload formats.
+type corsRequest struct {
+	HTTPMethod     string            `json:"httpMethod"`
+	Headers        map[string]string `json:"headers"`
+	RequestContext struct {
+		HTTP struct {
+			Method string `json:"method"`
+		} `json:"http"`
+	} `json:"requestContext"`
+}
+
+// CORSMiddleware answers preflight requests without calling the inner handler and adds
+// Access-Control-Allow-Origin to every APIResponse of an allowed origin.
+// The request's origin is echoed back instead of "*" so that credentialed requests work.
+func CORSMiddleware(opts CORSOptions) Middleware {
+	methods := opts.AllowedMethods
+	if len(methods) == 0 {
+		methods = defaultCORSMethods
+	}
+	return func(next Handler) Handler {
+		return func(ctx context.Context, event json.RawMessage) (any, error) {
+			var req corsRequest
+			if err := unmarshal(event, &req); err != nil {
+				return next(ctx, event)
+			}
+			method := req.RequestContext.HTTP.Method
+			if method == "" {
+				method = req.HTTPMethod
+			}
+			origin, _ := header(req.Headers, "Origin")
+			allowed := origin != "" && originAllowed(opts.AllowedOrigins, origin)
+
+			// preflight はルートのハンドラーまで届かせない
+			if requested, ok := header(req.Headers, "Access-Control-Request-Method"); strings.EqualFold(method, http.MethodOptions) && ok && requested != "" {
+				res := NoContent().withHeader("Vary", "Origin")
+				if !allowed {
+					return res, nil
+				}
+				res = opts.withAllowOrigin(res, origin).withHeader("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+				if len(opts.AllowedHeaders) > 0 {
+					res = res.withHeader("Access-Control-Allow-Headers", strings.Join(opts.AllowedHeaders, ", "))
+				} else if h, ok := header(req.Headers, "Access-Control-Request-Headers"); ok {
+					res = res.withHeader("Access-Control-Allow-Headers", h)
+				}
+				if opts.MaxAge > 0 {
+					res = res.withHeader("Access-Control-Max-Age", strconv.Itoa(opts.MaxAge))
+				}
+				return res, nil
+			}
+
+			res, err := next(ctx, event)
+			if err != nil || !allowed {
+				return res, err
+			}
+			apiRes, ok := asAPIResponse(res)
+			if !ok {
+				return res, nil
+			}
+			return opts.withAllowOrigin(apiRes, origin).withHeader("Vary", "Origin"), nil
+		}
+	}
+}
+
+func (opts CORSOptions) withAllowOrigin(res APIResponse, origin string) APIResponse {
+	res = res.withHeader("Access-Control-Allow-Origin", origin)
+	if opts.AllowCredentials {
+		res = res.withHeader("Access-Control-Allow-Credentials", "true")
+	}
+	return res
+}
+
+func originAllowed(patterns []string, origin string) bool {
+	for _, p := range patterns {
+		if p == "*" || strings.EqualFold(p, origin) {
+			return true
+		}
+		if prefix, suffix, ok := strings.Cut(p, "*"); ok &&
+			len(origin) >= len(prefix)+len(suffix) &&
+			strings.HasPrefix(strings.ToLower(origin), strings.ToLower(prefix)) &&
+			strings.HasSuffix(strings.ToLower(origin), strings.ToLower(suffix)) {
+			return true
+		}
+	}
+	return false
+}
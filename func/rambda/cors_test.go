@@ -0,0 +1,58 @@
+package rambda
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func corsEvent(method, path, origin string, extra string) json.RawMessage {
+	return json.RawMessage(fmt.Sprintf(`{"rawPath":%q,"headers":{"origin":%q%s},"requestContext":{"http":{"method":%q}}}`,
+		path, origin, extra, method))
+}
+
+func TestCORSMiddleware(t *testing.T) {
+	r := newTestRouter()
+	calls := 0
+	r.Use(CORSMiddleware(CORSOptions{
+		AllowedOrigins:   []string{"https://app.example.com", "https://*.preview.example.com"},
+		AllowedHeaders:   []string{"Content-Type"},
+		AllowCredentials: true,
+		MaxAge:           600,
+	}), func(next Handler) Handler {
+		return func(ctx context.Context, event json.RawMessage) (any, error) {
+			calls++
+			return next(ctx, event)
+		}
+	})
+	h := r.Handler()
+
+	res, err := h(context.Background(), corsEvent("OPTIONS", "/users", "https://pr-1.preview.example.com", `,"access-control-request-method":"POST"`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pre := res.(APIResponse)
+	if pre.StatusCode != http.StatusNoContent ||
+		pre.Headers["Access-Control-Allow-Origin"] != "https://pr-1.preview.example.com" ||
+		pre.Headers["Access-Control-Allow-Headers"] != "Content-Type" ||
+		pre.Headers["Access-Control-Allow-Credentials"] != "true" ||
+		pre.Headers["Access-Control-Max-Age"] != "600" ||
+		pre.Headers["Access-Control-Allow-Methods"] == "" {
+		t.Errorf("preflight = %+v", pre)
+	}
+	if calls != 0 {
+		t.Error("preflight reached the route handlers")
+	}
+
+	res, _ = h(context.Background(), corsEvent("GET", "/users", "https://app.example.com", ""))
+	if got := res.(APIResponse).Headers["Access-Control-Allow-Origin"]; got != "https://app.example.com" {
+		t.Errorf("allow origin = %q", got)
+	}
+
+	res, _ = h(context.Background(), corsEvent("GET", "/users", "https://evil.example.org", ""))
+	if got, ok := res.(APIResponse).Headers["Access-Control-Allow-Origin"]; ok {
+		t.Errorf("disallowed origin got allow origin %q", got)
+	}
+}
@@ -0,0 +1,172 @@
+// Package rambda provides helpers for writing AWS Lambda functions in Go.
+package rambda
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// EventSource identifies the AWS service that triggered an invocation.
+type EventSource string
+
+const (
+	SourceUnknown     EventSource = "unknown"
+	SourceAPIGateway  EventSource = "apigateway"
+	SourceSQS         EventSource = "aws:sqs"
+	SourceS3          EventSource = "aws:s3"
+	SourceDynamoDB    EventSource = "aws:dynamodb"
+	SourceKinesis     EventSource = "aws:kinesis"
+	SourceSNS         EventSource = "aws:sns"
+	SourceEventBridge EventSource = "aws:events"
+)
+
+// ErrNoHandler is returned by Dispatch when no handler is registered for the detected source.
+var ErrNoHandler = errors.New("rambda: no handler registered")
+
+// sourceProbe holds only the fields needed to tell event shapes apart.
+type sourceProbe struct {
+	Records []struct {
+		// SNS だけは "EventSource" だが大文字小文字を区別せずに一致する
+		EventSource string `json:"eventSource"`
+	} `json:"Records"`
+	DetailType     *string `json:"detail-type"`
+	HTTPMethod     string  `json:"httpMethod"`
+	RequestContext *struct {
+		HTTP json.RawMessage `json:"http"`
+	} `json:"requestContext"`
+}
+
+// DetectSource inspects the payload and reports which event source produced it.
+func DetectSource(payload json.RawMessage) EventSource {
+	var probe sourceProbe
+	if err := unmarshal(payload, &probe); err != nil {
+		return SourceUnknown
+	}
+
+	if len(probe.Records) > 0 {
+		switch source := EventSource(probe.Records[0].EventSource); source {
+		case SourceSQS, SourceS3, SourceDynamoDB, SourceKinesis, SourceSNS:
+			return source
+		}
+		return SourceUnknown
+	}
+
+	if probe.DetailType != nil {
+		return SourceEventBridge
+	}
+
+	// v2 (HTTP API) は requestContext.http、v1 (REST API) は httpMethod を持つ
+	if probe.RequestContext != nil && (len(probe.RequestContext.HTTP) > 0 || probe.HTTPMethod != "") {
+		return SourceAPIGateway
+	}
+	return SourceUnknown
+}
+
+// Dispatcher routes events to the handler registered for their source.
+//
+// A *Dispatcher can be passed directly to lambda.Start.
+type Dispatcher struct {
+	mu        sync.RWMutex
+	handlers  map[EventSource]Handler
+	detectors []detector
+	policies  map[EventSource]Policy
+}
+
+type detector struct {
+	source EventSource
+	detect func(json.RawMessage) bool
+}
+
+// NewDispatcher returns an empty Dispatcher.
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{
+		handlers: make(map[EventSource]Handler),
+		policies: make(map[EventSource]Policy),
+	}
+}
+
+// DefaultDispatcher is the Dispatcher used by Handle and Dispatch.
+var DefaultDispatcher = NewDispatcher()
+
+// Handle registers h for events from source, replacing any previous handler.
+func (d *Dispatcher) Handle(source EventSource, h Handler) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.handlers[source] = h
+}
+
+// RegisterDetector teaches d to recognize a custom event shape. Events for which detect
+// returns true are routed to the handler registered for EventSource(name).
+//
+// Detectors run in registration order before the built-in detection of DetectSource, and
+// the first match wins, so they can also override how AWS events are routed. A detector
+// that panics, for example on unexpected JSON, is treated as not matching.
+func (d *Dispatcher) RegisterDetector(name string, detect func(json.RawMessage) bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.detectors = append(d.detectors, detector{EventSource(name), detect})
+}
+
+func (d *Dispatcher) detect(event json.RawMessage) EventSource {
+	d.mu.RLock()
+	detectors := d.detectors
+	d.mu.RUnlock()
+
+	for _, det := range detectors {
+		if safeDetect(det.detect, event) {
+			return det.source
+		}
+	}
+	return DetectSource(event)
+}
+
+func safeDetect(detect func(json.RawMessage) bool, event json.RawMessage) (matched bool) {
+	defer func() {
+		if recover() != nil {
+			matched = false
+		}
+	}()
+	return detect(event)
+}
+
+// Dispatch detects the source of event and invokes the matching handler.
+func (d *Dispatcher) Dispatch(ctx context.Context, event json.RawMessage) (any, error) {
+	source := d.detect(event)
+
+	d.mu.RLock()
+	h, ok := d.handlers[source]
+	policy, hasPolicy := d.policies[source]
+	d.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w for source %q", ErrNoHandler, source)
+	}
+	if hasPolicy {
+		return policy.apply(ctx, source, h, event)
+	}
+	return h(ctx, event)
+}
+
+// Invoke implements lambda.Handler.
+func (d *Dispatcher) Invoke(ctx context.Context, payload []byte) ([]byte, error) {
+	return Handler(d.Dispatch).Invoke(ctx, payload)
+}
+
+// Handle registers h on DefaultDispatcher.
+func Handle(source EventSource, h Handler) {
+	DefaultDispatcher.Handle(source, h)
+}
+
+// RegisterDetector registers a custom detector on DefaultDispatcher.
+func RegisterDetector(name string, detect func(json.RawMessage) bool) {
+	DefaultDispatcher.RegisterDetector(name, detect)
+}
+
+// Dispatch routes event through DefaultDispatcher.
+// Pass DefaultDispatcher or rambda.Handler(rambda.Dispatch) to lambda.Start rather than
+// the bare function, so that IsColdStart, SetMarshaler and SetEnvelope take effect.
+func Dispatch(ctx context.Context, event json.RawMessage) (any, error) {
+	return DefaultDispatcher.Dispatch(ctx, event)
+}
@@ -0,0 +1,125 @@
+package rambda
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestDetectSource(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		want    EventSource
+	}{
+		{"api gateway v2", `{"rawPath":"/","requestContext":{"http":{"method":"GET"}}}`, SourceAPIGateway},
+		{"api gateway v1", `{"httpMethod":"GET","requestContext":{"stage":"prod"}}`, SourceAPIGateway},
+		{"sqs", `{"Records":[{"eventSource":"aws:sqs","body":"hi"}]}`, SourceSQS},
+		{"s3", `{"Records":[{"eventSource":"aws:s3","s3":{}}]}`, SourceS3},
+		{"dynamodb", `{"Records":[{"eventSource":"aws:dynamodb","dynamodb":{}}]}`, SourceDynamoDB},
+		{"kinesis", `{"Records":[{"eventSource":"aws:kinesis","kinesis":{}}]}`, SourceKinesis},
+		{"sns", `{"Records":[{"EventSource":"aws:sns","Sns":{}}]}`, SourceSNS},
+		{"eventbridge", `{"source":"orders","detail-type":"OrderPlaced","detail":{}}`, SourceEventBridge},
+		{"unknown record", `{"Records":[{"eventSource":"aws:other"}]}`, SourceUnknown},
+		{"plain object", `{"key":"value"}`, SourceUnknown},
+		{"invalid json", `{`, SourceUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectSource(json.RawMessage(tt.payload)); got != tt.want {
+				t.Errorf("DetectSource() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDispatcher(t *testing.T) {
+	d := NewDispatcher()
+	d.Handle(SourceSQS, func(ctx context.Context, event json.RawMessage) (any, error) {
+		return "sqs", nil
+	})
+
+	got, err := d.Dispatch(context.Background(), json.RawMessage(`{"Records":[{"eventSource":"aws:sqs"}]}`))
+	if err != nil {
+		t.Fatalf("Dispatch() error = %v", err)
+	}
+	if got != "sqs" {
+		t.Errorf("Dispatch() = %v, want sqs", got)
+	}
+
+	_, err = d.Dispatch(context.Background(), json.RawMessage(`{"Records":[{"eventSource":"aws:s3"}]}`))
+	if !errors.Is(err, ErrNoHandler) {
+		t.Fatalf("Dispatch() error = %v, want ErrNoHandler", err)
+	}
+	if want := `rambda: no handler registered for source "aws:s3"`; err.Error() != want {
+		t.Errorf("Dispatch() error = %q, want %q", err.Error(), want)
+	}
+}
+
+func TestDispatcherInvoke(t *testing.T) {
+	resetColdStart()
+	t.Cleanup(resetColdStart)
+
+	var cold []bool
+	d := NewDispatcher()
+	d.Handle(SourceSQS, func(ctx context.Context, event json.RawMessage) (any, error) {
+		cold = append(cold, IsColdStart())
+		return "sqs", nil
+	})
+
+	for i := 0; i < 2; i++ {
+		b, err := d.Invoke(context.Background(), []byte(`{"Records":[{"eventSource":"aws:sqs"}]}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != `"sqs"` {
+			t.Errorf("Invoke() = %s, want \"sqs\"", b)
+		}
+	}
+	if len(cold) != 2 || !cold[0] || cold[1] {
+		t.Errorf("IsColdStart() = %v, want [true false]", cold)
+	}
+}
+
+func TestDispatcherRegisterDetector(t *testing.T) {
+	d := NewDispatcher()
+	reply := func(s string) Handler {
+		return func(ctx context.Context, event json.RawMessage) (any, error) { return s, nil }
+	}
+	d.Handle(SourceSQS, reply("sqs"))
+	d.Handle("github", reply("github"))
+	d.Handle("first", reply("first"))
+	d.Handle("second", reply("second"))
+
+	d.RegisterDetector("broken", func(event json.RawMessage) bool {
+		var m map[string]any
+		json.Unmarshal(event, &m)
+		return m["repository"].(map[string]any) != nil
+	})
+	d.RegisterDetector("github", func(event json.RawMessage) bool {
+		return bytes.Contains(event, []byte(`"repository"`))
+	})
+	d.RegisterDetector("first", func(event json.RawMessage) bool {
+		return bytes.Contains(event, []byte(`"custom"`))
+	})
+	d.RegisterDetector("second", func(event json.RawMessage) bool {
+		return bytes.Contains(event, []byte(`"custom"`))
+	})
+
+	tests := []struct {
+		payload string
+		want    string
+	}{
+		{`{"repository":"rambda"}`, "github"},
+		{`{"custom":true}`, "first"},
+		{`{"Records":[{"eventSource":"aws:sqs"}]}`, "sqs"},
+	}
+	for _, tt := range tests {
+		got, err := d.Dispatch(context.Background(), json.RawMessage(tt.payload))
+		if err != nil || got != tt.want {
+			t.Errorf("Dispatch(%s) = %v, %v; want %s", tt.payload, got, err, tt.want)
+		}
+	}
+}
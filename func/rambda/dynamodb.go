@@ -0,0 +1,118 @@
+package rambda
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// DynamoDB stream event names.
+const (
+	EventInsert = "INSERT"
+	EventModify = "MODIFY"
+	EventRemove = "REMOVE"
+)
+
+// Change is a DynamoDB stream record with its images decoded into T.
+// OldImage is nil for INSERT and NewImage is nil for REMOVE, as is either one when
+// the stream view type does not include it.
+type Change[T any] struct {
+	EventName string
+	OldImage  *T
+	NewImage  *T
+}
+
+// DynamoStreamHandler adapts a per-change function into a Handler for DynamoDB streams.
+// Records are processed in order and the first error stops the batch so that the
+// stream is retried from the failing record.
+func DynamoStreamHandler[T any](fn func(ctx context.Context, change Change[T]) error) Handler {
+	return func(ctx context.Context, event json.RawMessage) (any, error) {
+		var streamEvent events.DynamoDBEvent
+		if err := unmarshal(event, &streamEvent); err != nil {
+			return nil, newDecodeError(&streamEvent, event, err)
+		}
+
+		for _, record := range streamEvent.Records {
+			change := Change[T]{EventName: record.EventName}
+			var err error
+			if change.OldImage, err = decodeImage[T](record.Change.OldImage); err != nil {
+				return nil, fmt.Errorf("rambda: decode old image of %s: %w", record.EventID, err)
+			}
+			if change.NewImage, err = decodeImage[T](record.Change.NewImage); err != nil {
+				return nil, fmt.Errorf("rambda: decode new image of %s: %w", record.EventID, err)
+			}
+			if err := fn(ctx, change); err != nil {
+				return nil, fmt.Errorf("rambda: dynamodb record %s: %w", record.EventID, err)
+			}
+		}
+		return nil, nil
+	}
+}
+
+func decodeImage[T any](image map[string]events.DynamoDBAttributeValue) (*T, error) {
+	if len(image) == 0 {
+		return nil, nil
+	}
+	item := make(map[string]types.AttributeValue, len(image))
+	for k, v := range image {
+		av, err := toAttributeValue(v)
+		if err != nil {
+			return nil, err
+		}
+		item[k] = av
+	}
+	var out T
+	if err := attributevalue.UnmarshalMap(item, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// toAttributeValue converts the Lambda event representation into the SDK one understood by attributevalue.
+func toAttributeValue(v events.DynamoDBAttributeValue) (types.AttributeValue, error) {
+	switch v.DataType() {
+	case events.DataTypeString:
+		return &types.AttributeValueMemberS{Value: v.String()}, nil
+	case events.DataTypeNumber:
+		return &types.AttributeValueMemberN{Value: v.Number()}, nil
+	case events.DataTypeBinary:
+		return &types.AttributeValueMemberB{Value: v.Binary()}, nil
+	case events.DataTypeBoolean:
+		return &types.AttributeValueMemberBOOL{Value: v.Boolean()}, nil
+	case events.DataTypeNull:
+		return &types.AttributeValueMemberNULL{Value: true}, nil
+	case events.DataTypeStringSet:
+		return &types.AttributeValueMemberSS{Value: v.StringSet()}, nil
+	case events.DataTypeNumberSet:
+		return &types.AttributeValueMemberNS{Value: v.NumberSet()}, nil
+	case events.DataTypeBinarySet:
+		return &types.AttributeValueMemberBS{Value: v.BinarySet()}, nil
+	case events.DataTypeList:
+		list := v.List()
+		out := make([]types.AttributeValue, len(list))
+		for i, item := range list {
+			av, err := toAttributeValue(item)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = av
+		}
+		return &types.AttributeValueMemberL{Value: out}, nil
+	case events.DataTypeMap:
+		m := v.Map()
+		out := make(map[string]types.AttributeValue, len(m))
+		for k, item := range m {
+			av, err := toAttributeValue(item)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = av
+		}
+		return &types.AttributeValueMemberM{Value: out}, nil
+	}
+	return nil, fmt.Errorf("rambda: unsupported dynamodb data type %d", v.DataType())
+}
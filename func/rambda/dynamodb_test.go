@@ -0,0 +1,56 @@
+package rambda
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+type user struct {
+	ID    string   `dynamodbav:"id"`
+	Age   int      `dynamodbav:"age"`
+	Tags  []string `dynamodbav:"tags"`
+	Admin bool     `dynamodbav:"admin"`
+}
+
+func TestDynamoStreamHandler(t *testing.T) {
+	payload := `{"Records":[
+		{"eventID":"1","eventName":"INSERT","eventSource":"aws:dynamodb","dynamodb":{
+			"NewImage":{"id":{"S":"u1"},"age":{"N":"20"},"tags":{"SS":["a","b"]},"admin":{"BOOL":true}}}},
+		{"eventID":"2","eventName":"MODIFY","eventSource":"aws:dynamodb","dynamodb":{
+			"OldImage":{"id":{"S":"u1"},"age":{"N":"20"}},
+			"NewImage":{"id":{"S":"u1"},"age":{"N":"21"}}}},
+		{"eventID":"3","eventName":"REMOVE","eventSource":"aws:dynamodb","dynamodb":{
+			"OldImage":{"id":{"S":"u1"},"age":{"N":"21"}}}}
+	]}`
+
+	var changes []Change[user]
+	h := DynamoStreamHandler(func(ctx context.Context, change Change[user]) error {
+		changes = append(changes, change)
+		return nil
+	})
+	if _, err := h(context.Background(), json.RawMessage(payload)); err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 3 {
+		t.Fatalf("got %d changes, want 3", len(changes))
+	}
+
+	insert := changes[0]
+	if insert.EventName != EventInsert || insert.OldImage != nil || insert.NewImage == nil {
+		t.Fatalf("insert = %+v", insert)
+	}
+	if got := *insert.NewImage; got.ID != "u1" || got.Age != 20 || len(got.Tags) != 2 || !got.Admin {
+		t.Errorf("insert new image = %+v", got)
+	}
+
+	modify := changes[1]
+	if modify.OldImage.Age != 20 || modify.NewImage.Age != 21 {
+		t.Errorf("modify = %+v -> %+v", modify.OldImage, modify.NewImage)
+	}
+
+	remove := changes[2]
+	if remove.EventName != EventRemove || remove.NewImage != nil || remove.OldImage.Age != 21 {
+		t.Errorf("remove = %+v", remove)
+	}
+}
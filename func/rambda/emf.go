@@ -0,0 +1,80 @@
+package rambda
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// emfOutput is where Embedded Metric Format documents are written.
+// Lambda forwards stdout to CloudWatch Logs, which extracts the metrics.
+var (
+	emfOutput   io.Writer = os.Stdout
+	emfOutputMu sync.Mutex
+)
+
+// emfValue is a single metric datum in an EMF document.
+type emfValue struct {
+	Name  string
+	Unit  string
+	Value float64
+}
+
+type emfMetricDirective struct {
+	Namespace  string          `json:"Namespace"`
+	Dimensions [][]string      `json:"Dimensions"`
+	Metrics    []emfMetricSpec `json:"Metrics"`
+}
+
+type emfMetricSpec struct {
+	Name string `json:"Name"`
+	Unit string `json:"Unit,omitempty"`
+}
+
+// writeEMF writes one EMF document containing values to emfOutput as a single line.
+func writeEMF(namespace string, dimensions map[string]string, values []emfValue) error {
+	keys := make([]string, 0, len(dimensions))
+	for k := range dimensions {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	directive := emfMetricDirective{
+		Namespace:  namespace,
+		Dimensions: [][]string{keys},
+		Metrics:    make([]emfMetricSpec, 0, len(values)),
+	}
+	doc := make(map[string]any, len(dimensions)+len(values)+1)
+	for k, v := range dimensions {
+		doc[k] = v
+	}
+	for _, v := range values {
+		// 同じ名前のメトリクスは値の配列としてまとめる
+		switch prev := doc[v.Name].(type) {
+		case float64:
+			doc[v.Name] = []float64{prev, v.Value}
+			continue
+		case []float64:
+			doc[v.Name] = append(prev, v.Value)
+			continue
+		}
+		doc[v.Name] = v.Value
+		directive.Metrics = append(directive.Metrics, emfMetricSpec{Name: v.Name, Unit: v.Unit})
+	}
+	doc["_aws"] = map[string]any{
+		"Timestamp":         time.Now().UnixMilli(),
+		"CloudWatchMetrics": []emfMetricDirective{directive},
+	}
+
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	emfOutputMu.Lock()
+	defer emfOutputMu.Unlock()
+	_, err = emfOutput.Write(append(b, '\n'))
+	return err
+}
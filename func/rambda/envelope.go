@@ -0,0 +1,110 @@
+package rambda
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sync"
+)
+
+// Envelope shapes the payloads returned by handlers.
+// Implement it to match an API contract such as {"data": ...} or JSON:API.
+// No envelope is applied unless one is set with SetEnvelope or EnvelopeMiddleware is used.
+type Envelope interface {
+	// Wrap turns a successful handler result into the returned payload.
+	Wrap(data any) any
+	// WrapError turns a handler error into the returned payload.
+	WrapError(err error) any
+}
+
+// Response is the {"message", "input"} payload of DefaultEnvelope.
+type Response struct {
+	Message string `json:"message"`
+	Input   any    `json:"input"`
+}
+
+// DefaultEnvelope wraps results as Response{Message: "OK", Input: data} and errors as
+// Response{Message: err.Error()}. Results that already are a Response are left as they are.
+var DefaultEnvelope Envelope = defaultEnvelope{}
+
+type defaultEnvelope struct{}
+
+func (defaultEnvelope) Wrap(data any) any {
+	switch data.(type) {
+	case Response, *Response:
+		return data
+	}
+	return Response{Message: "OK", Input: data}
+}
+
+func (defaultEnvelope) WrapError(err error) any {
+	return Response{Message: err.Error()}
+}
+
+var (
+	envelopeMu sync.RWMutex
+	envelopeV  Envelope
+)
+
+// SetEnvelope makes Handler.Invoke, and so Start and Invoke, wrap successful results with e.
+// It also replaces the envelope used by EnvelopeMiddleware. Passing nil turns wrapping off
+// again and makes EnvelopeMiddleware use DefaultEnvelope.
+func SetEnvelope(e Envelope) {
+	envelopeMu.Lock()
+	defer envelopeMu.Unlock()
+	envelopeV = e
+}
+
+// configuredEnvelope returns the envelope set with SetEnvelope, if any.
+func configuredEnvelope() (Envelope, bool) {
+	envelopeMu.RLock()
+	defer envelopeMu.RUnlock()
+	return envelopeV, envelopeV != nil
+}
+
+func currentEnvelope() Envelope {
+	if env, ok := configuredEnvelope(); ok {
+		return env
+	}
+	return DefaultEnvelope
+}
+
+// EnvelopeMiddleware wraps results and errors of the inner handler with the envelope set by
+// SetEnvelope, or DefaultEnvelope. Errors become successful payloads built by WrapError, so
+// the invocation does not fail. Results with a shape Lambda relies on are returned unchanged,
+// see wrapResult.
+func EnvelopeMiddleware() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, event json.RawMessage) (any, error) {
+			res, err := next(ctx, event)
+			if state := invocationFromContext(ctx); state != nil {
+				state.wrapped = true
+			}
+			env := currentEnvelope()
+			if err != nil {
+				return env.WrapError(err), nil
+			}
+			return wrapResult(env, res), nil
+		}
+	}
+}
+
+const eventsPkgPath = "github.com/aws/aws-lambda-go/events"
+
+// wrapResult applies env to v unless v is an APIResponse or a response type of the
+// aws-lambda-go events package, such as events.SQSEventResponse, whose shape API Gateway
+// or the event source mapping depends on.
+func wrapResult(env Envelope, v any) any {
+	if _, ok := asAPIResponse(v); ok {
+		return v
+	}
+	if t := reflect.TypeOf(v); t != nil {
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if t.PkgPath() == eventsPkgPath {
+			return v
+		}
+	}
+	return env.Wrap(v)
+}
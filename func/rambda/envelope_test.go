@@ -0,0 +1,95 @@
+package rambda
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+type dataEnvelope struct{}
+
+func (dataEnvelope) Wrap(data any) any       { return map[string]any{"data": data} }
+func (dataEnvelope) WrapError(err error) any { return map[string]any{"errors": []string{err.Error()}} }
+
+func TestEnvelopeOptIn(t *testing.T) {
+	h := Typed(func(ctx context.Context, in map[string]any) (any, error) {
+		return in["name"], nil
+	})
+
+	out, err := Invoke(h, []byte(`{"name":"gopher"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(out); got != `"gopher"` {
+		t.Errorf("without SetEnvelope = %s, want the result as is", got)
+	}
+
+	SetEnvelope(dataEnvelope{})
+	defer SetEnvelope(nil)
+	out, err = Invoke(h, []byte(`{"name":"gopher"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(out); got != `{"data":"gopher"}` {
+		t.Errorf("custom envelope = %s", got)
+	}
+}
+
+func TestEnvelopeSkipsLambdaResponses(t *testing.T) {
+	SetEnvelope(dataEnvelope{})
+	defer SetEnvelope(nil)
+
+	for _, res := range []any{
+		Text(200, "hi"),
+		events.SQSEventResponse{BatchItemFailures: []events.SQSBatchItemFailure{{ItemIdentifier: "m-1"}}},
+		&events.APIGatewayV2HTTPResponse{StatusCode: 200},
+	} {
+		h := Handler(func(ctx context.Context, event json.RawMessage) (any, error) { return res, nil })
+		out, err := h.Invoke(context.Background(), []byte(`{}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, _ := encodeResponse(res)
+		if string(out) != string(want) {
+			t.Errorf("%T was wrapped: %s", res, out)
+		}
+	}
+}
+
+func TestEnvelopeMiddlewareError(t *testing.T) {
+	SetEnvelope(dataEnvelope{})
+	defer SetEnvelope(nil)
+
+	calls := 0
+	h := Chain(func(ctx context.Context, event json.RawMessage) (any, error) {
+		calls++
+		if calls > 1 {
+			return "ok", nil
+		}
+		return nil, errors.New("boom")
+	}, EnvelopeMiddleware())
+
+	out, err := h.Invoke(context.Background(), []byte(`{}`))
+	if err != nil {
+		t.Fatalf("error should be wrapped into the payload, got %v", err)
+	}
+	if got := string(out); got != `{"errors":["boom"]}` {
+		t.Errorf("payload = %s", got)
+	}
+	if out, _ := h.Invoke(context.Background(), []byte(`{}`)); string(out) != `{"data":"ok"}` {
+		t.Errorf("result wrapped by the middleware should not be wrapped again, got %s", out)
+	}
+}
+
+func TestEnvelopeMiddlewareDefault(t *testing.T) {
+	h := Chain(func(ctx context.Context, event json.RawMessage) (any, error) {
+		return "gopher", nil
+	}, EnvelopeMiddleware())
+	out, err := h.Invoke(context.Background(), []byte(`{}`))
+	if err != nil || string(out) != `{"message":"OK","input":"gopher"}` {
+		t.Errorf("default envelope = %s, %v", out, err)
+	}
+}
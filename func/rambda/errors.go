@@ -0,0 +1,151 @@
+package rambda
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+)
+
+var (
+	// ErrBadRequest is mapped to 400 by DefaultErrorMapper.
+	ErrBadRequest = errors.New("rambda: bad request")
+	// ErrNotFound is mapped to 404 by DefaultErrorMapper.
+	ErrNotFound = errors.New("rambda: not found")
+	// ErrUnauthorized is mapped to 401 by DefaultErrorMapper.
+	ErrUnauthorized = errors.New("rambda: unauthorized")
+)
+
+// APIError is an error with a stable, machine-readable code for API clients.
+// DefaultErrorMapper renders it with Status and the body
+// {"error": {"code": ..., "message": ..., "details": ...}}.
+type APIError struct {
+	Code    string         `json:"code"`
+	Message string         `json:"message"`
+	Status  int            `json:"-"`
+	Details map[string]any `json:"details,omitempty"`
+}
+
+// NewAPIError returns an APIError without details.
+func NewAPIError(code, message string, status int) *APIError {
+	return &APIError{Code: code, Message: message, Status: status}
+}
+
+func (e *APIError) Error() string {
+	return "rambda: " + e.Code + ": " + e.Message
+}
+
+// APIResponse renders the error for API Gateway callers. A zero Status is sent as 500.
+func (e *APIError) APIResponse() APIResponse {
+	status := e.Status
+	if status == 0 {
+		status = http.StatusInternalServerError
+	}
+	return JSON(status, map[string]*APIError{"error": e})
+}
+
+// ErrorMapper turns a handler error into the response returned to API Gateway callers.
+type ErrorMapper interface {
+	MapError(err error) APIResponse
+}
+
+// ErrorMapperFunc adapts a function into an ErrorMapper.
+type ErrorMapperFunc func(err error) APIResponse
+
+func (f ErrorMapperFunc) MapError(err error) APIResponse {
+	return f(err)
+}
+
+// DefaultErrorMapper maps errors registered with RegisterErrorStatus to their status,
+// *APIError to its own status and body, *ValidationError and ErrBadRequest to 400, ErrNotFound to 404, ErrUnauthorized to 401,
+// ErrIdempotencyInProgress to 409, *PayloadTooLargeError to 413 and anything else to 500.
+// Wrapped errors are unwrapped when matching.
+//
+// The body is {"error": "..."}; for 5xx statuses the message is the status text
+// so that internal details are not leaked to callers.
+var DefaultErrorMapper ErrorMapper = ErrorMapperFunc(mapError)
+
+type errorStatus struct {
+	err    error
+	status int
+}
+
+var (
+	errorStatusesMu sync.RWMutex
+	errorStatuses   []errorStatus
+)
+
+// builtinErrorStatuses are checked after the registered ones.
+var builtinErrorStatuses = []errorStatus{
+	{ErrBadRequest, http.StatusBadRequest},
+	{ErrNotFound, http.StatusNotFound},
+	{ErrUnauthorized, http.StatusUnauthorized},
+	{ErrIdempotencyInProgress, http.StatusConflict},
+}
+
+// RegisterErrorStatus makes DefaultErrorMapper respond with status to any error matching err with errors.Is.
+// Registrations are checked in order before the built-in mappings.
+func RegisterErrorStatus(err error, status int) {
+	errorStatusesMu.Lock()
+	defer errorStatusesMu.Unlock()
+	errorStatuses = append(errorStatuses, errorStatus{err, status})
+}
+
+func mapError(err error) APIResponse {
+	errorStatusesMu.RLock()
+	registered := errorStatuses
+	errorStatusesMu.RUnlock()
+
+	for _, es := range registered {
+		if errors.Is(err, es.err) {
+			return errorResponse(es.status, err)
+		}
+	}
+
+	var ae *APIError
+	if errors.As(err, &ae) {
+		return ae.APIResponse()
+	}
+	var ve *ValidationError
+	if errors.As(err, &ve) {
+		return ve.APIResponse()
+	}
+	var pe *PayloadTooLargeError
+	if errors.As(err, &pe) {
+		return errorResponse(http.StatusRequestEntityTooLarge, err)
+	}
+
+	for _, es := range builtinErrorStatuses {
+		if errors.Is(err, es.err) {
+			return errorResponse(es.status, err)
+		}
+	}
+	return errorResponse(http.StatusInternalServerError, err)
+}
+
+func errorResponse(status int, err error) APIResponse {
+	msg := err.Error()
+	if status >= http.StatusInternalServerError {
+		msg = http.StatusText(status)
+	}
+	return JSON(status, map[string]string{"error": msg})
+}
+
+// ErrorMiddleware converts errors returned by the inner handler into responses using m,
+// so API Gateway callers see a meaningful status instead of a 502.
+// If m is nil, DefaultErrorMapper is used.
+func ErrorMiddleware(m ErrorMapper) Middleware {
+	if m == nil {
+		m = DefaultErrorMapper
+	}
+	return func(next Handler) Handler {
+		return func(ctx context.Context, event json.RawMessage) (any, error) {
+			res, err := next(ctx, event)
+			if err != nil {
+				return m.MapError(err), nil
+			}
+			return res, nil
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package rambda
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestDefaultErrorMapper(t *testing.T) {
+	errConflict := errors.New("conflict")
+	RegisterErrorStatus(errConflict, http.StatusConflict)
+	t.Cleanup(func() { errorStatuses = nil })
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantBody   string
+	}{
+		{"not found", fmt.Errorf("user 1: %w", ErrNotFound), http.StatusNotFound, `{"error":"user 1: rambda: not found"}`},
+		{"unauthorized", ErrUnauthorized, http.StatusUnauthorized, `{"error":"rambda: unauthorized"}`},
+		{"validation", fmt.Errorf("wrapped: %w", &ValidationError{Fields: []FieldError{{Field: "id", Rule: "required", Message: "is required"}}}),
+			http.StatusBadRequest, `{"error":"validation failed","fields":[{"field":"id","rule":"required","message":"is required"}]}`},
+		{"registered", fmt.Errorf("save: %w", errConflict), http.StatusConflict, `{"error":"save: conflict"}`},
+		{"other", errors.New("db password is hunter2"), http.StatusInternalServerError, `{"error":"Internal Server Error"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := DefaultErrorMapper.MapError(tt.err)
+			if res.StatusCode != tt.wantStatus || res.Body != tt.wantBody {
+				t.Errorf("MapError() = %d %s, want %d %s", res.StatusCode, res.Body, tt.wantStatus, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestAPIHandlerMapsErrors(t *testing.T) {
+	h := APIHandler(func(ctx context.Context, req *APIRequest) (APIResponse, error) {
+		if req.PathParameters["id"] == "missing" {
+			return APIResponse{}, ErrNotFound
+		}
+		return Text(http.StatusOK, req.RawPath), nil
+	})
+
+	res, err := h(context.Background(), json.RawMessage(`{"rawPath":"/users/1","pathParameters":{"id":"1"}}`))
+	if err != nil || res.(APIResponse).Body != "/users/1" {
+		t.Errorf("got (%v, %v)", res, err)
+	}
+
+	res, err = h(context.Background(), json.RawMessage(`{"pathParameters":{"id":"missing"}}`))
+	if err != nil || res.(APIResponse).StatusCode != http.StatusNotFound {
+		t.Errorf("got (%v, %v), want 404", res, err)
+	}
+}
+
+func TestErrorMiddleware(t *testing.T) {
+	h := Chain(func(ctx context.Context, event json.RawMessage) (any, error) {
+		return nil, ErrUnauthorized
+	}, ErrorMiddleware(nil))
+
+	res, err := h(context.Background(), nil)
+	if err != nil || res.(APIResponse).StatusCode != http.StatusUnauthorized {
+		t.Errorf("got (%v, %v), want 401", res, err)
+	}
+}
+
+func TestAPIError(t *testing.T) {
+	apiErr := NewAPIError("ORDER_LOCKED", "order is being processed", http.StatusConflict)
+	apiErr.Details = map[string]any{"orderId": "o-1"}
+
+	h := Chain(func(ctx context.Context, event json.RawMessage) (any, error) {
+		return nil, fmt.Errorf("update order: %w", apiErr)
+	}, ErrorMiddleware(nil))
+
+	res, err := h(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := res.(APIResponse)
+	want := `{"error":{"code":"ORDER_LOCKED","message":"order is being processed","details":{"orderId":"o-1"}}}`
+	if r.StatusCode != http.StatusConflict || r.Body != want {
+		t.Errorf("response = %d %s, want 409 %s", r.StatusCode, r.Body, want)
+	}
+
+	r = DefaultErrorMapper.MapError(NewAPIError("BROKEN", "upstream failed", 0))
+	if r.StatusCode != http.StatusInternalServerError || r.Body != `{"error":{"code":"BROKEN","message":"upstream failed"}}` {
+		t.Errorf("zero status response = %d %s", r.StatusCode, r.Body)
+	}
+}
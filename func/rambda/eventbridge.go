@@ -0,0 +1,73 @@
+package rambda
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrUnhandledDetailType is returned by EventBridgeHandler and EventBridgeMux for events
+// whose detail-type no handler is registered for.
+var ErrUnhandledDetailType = errors.New("rambda: unhandled detail-type")
+
+// EventBridgeMeta identifies the EventBridge event being handled.
+type EventBridgeMeta struct {
+	ID         string
+	Source     string
+	DetailType string
+}
+
+type eventBridgeKey struct{}
+
+// EventBridgeFromContext returns the metadata of the event passed to an EventBridgeHandler.
+func EventBridgeFromContext(ctx context.Context) (EventBridgeMeta, bool) {
+	m, ok := ctx.Value(eventBridgeKey{}).(EventBridgeMeta)
+	return m, ok
+}
+
+type eventBridgeEvent struct {
+	ID         string          `json:"id"`
+	Source     string          `json:"source"`
+	DetailType string          `json:"detail-type"`
+	Detail     json.RawMessage `json:"detail"`
+}
+
+// EventBridgeHandler adapts fn into a Handler for EventBridge events of detailType.
+// The detail object is decoded into T, and the event's id and source are available in
+// fn's context through EventBridgeFromContext. Events of other detail types fail with
+// ErrUnhandledDetailType.
+func EventBridgeHandler[T any](detailType string, fn func(ctx context.Context, detail T) error) Handler {
+	return func(ctx context.Context, event json.RawMessage) (any, error) {
+		var e eventBridgeEvent
+		if err := unmarshal(event, &e); err != nil {
+			return nil, newDecodeError(&e, event, err)
+		}
+		if e.DetailType != detailType {
+			return nil, fmt.Errorf("%w %q", ErrUnhandledDetailType, e.DetailType)
+		}
+
+		var detail T
+		if err := unmarshal(e.Detail, &detail); err != nil {
+			return nil, newDecodeError(&detail, e.Detail, err)
+		}
+		ctx = context.WithValue(ctx, eventBridgeKey{}, EventBridgeMeta{ID: e.ID, Source: e.Source, DetailType: e.DetailType})
+		return nil, fn(ctx, detail)
+	}
+}
+
+// EventBridgeMux combines EventBridgeHandlers for different detail types into one Handler.
+// Each event goes to the first handler that does not reject it with ErrUnhandledDetailType.
+func EventBridgeMux(handlers ...Handler) Handler {
+	return func(ctx context.Context, event json.RawMessage) (any, error) {
+		for _, h := range handlers {
+			res, err := h(ctx, event)
+			if !errors.Is(err, ErrUnhandledDetailType) {
+				return res, err
+			}
+		}
+		var e eventBridgeEvent
+		_ = unmarshal(event, &e)
+		return nil, fmt.Errorf("%w %q", ErrUnhandledDetailType, e.DetailType)
+	}
+}
@@ -0,0 +1,69 @@
+package rambda
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+type orderPlaced struct {
+	OrderID string `json:"orderId"`
+}
+
+type orderCancelled struct {
+	Reason string `json:"reason"`
+}
+
+func TestEventBridgeHandler(t *testing.T) {
+	var got orderPlaced
+	var meta EventBridgeMeta
+	h := EventBridgeHandler("OrderPlaced", func(ctx context.Context, detail orderPlaced) error {
+		got = detail
+		meta, _ = EventBridgeFromContext(ctx)
+		return nil
+	})
+
+	event := json.RawMessage(`{"id":"evt-1","source":"shop.orders","detail-type":"OrderPlaced","detail":{"orderId":"o-1"}}`)
+	if _, err := h(context.Background(), event); err != nil {
+		t.Fatal(err)
+	}
+	if got.OrderID != "o-1" {
+		t.Errorf("detail = %+v", got)
+	}
+	if meta.ID != "evt-1" || meta.Source != "shop.orders" {
+		t.Errorf("context metadata = %+v", meta)
+	}
+
+	_, err := h(context.Background(), json.RawMessage(`{"detail-type":"OrderShipped","detail":{}}`))
+	if !errors.Is(err, ErrUnhandledDetailType) {
+		t.Errorf("error = %v, want ErrUnhandledDetailType", err)
+	}
+}
+
+func TestEventBridgeMux(t *testing.T) {
+	var handled []string
+	h := EventBridgeMux(
+		EventBridgeHandler("OrderPlaced", func(ctx context.Context, detail orderPlaced) error {
+			handled = append(handled, "placed:"+detail.OrderID)
+			return nil
+		}),
+		EventBridgeHandler("OrderCancelled", func(ctx context.Context, detail orderCancelled) error {
+			handled = append(handled, "cancelled:"+detail.Reason)
+			return errors.New("refund failed")
+		}),
+	)
+
+	if _, err := h(context.Background(), json.RawMessage(`{"detail-type":"OrderPlaced","detail":{"orderId":"o-1"}}`)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h(context.Background(), json.RawMessage(`{"detail-type":"OrderCancelled","detail":{"reason":"late"}}`)); err == nil || err.Error() != "refund failed" {
+		t.Errorf("handler error = %v, want it passed through", err)
+	}
+	if _, err := h(context.Background(), json.RawMessage(`{"detail-type":"OrderShipped","detail":{}}`)); !errors.Is(err, ErrUnhandledDetailType) {
+		t.Errorf("error = %v, want ErrUnhandledDetailType", err)
+	}
+	if len(handled) != 2 || handled[0] != "placed:o-1" || handled[1] != "cancelled:late" {
+		t.Errorf("handled = %v", handled)
+	}
+}
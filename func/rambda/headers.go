@@ -0,0 +1,55 @@
+package rambda
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// RequestIDHeader is the response header HeadersMiddleware and HeadersFunc echo the AWS request ID in.
+const RequestIDHeader = "X-Request-Id"
+
+// HeadersMiddleware adds headers to every APIResponse returned by the inner handler,
+// for example security headers:
+//
+//	rambda.HeadersMiddleware(map[string]string{
+//		"X-Content-Type-Options":    "nosniff",
+//		"Strict-Transport-Security": "max-age=63072000; includeSubDomains",
+//	})
+//
+// See HeadersFunc for how headers are merged.
+func HeadersMiddleware(headers map[string]string) Middleware {
+	return HeadersFunc(func(context.Context) map[string]string { return headers })
+}
+
+// HeadersFunc adds the headers returned by fn for each invocation to every APIResponse
+// returned by the inner handler. The AWS request ID of the invocation is added as
+// RequestIDHeader. Headers the handler already set, compared case-insensitively, are kept
+// as they are, and results other than an APIResponse are returned unchanged.
+func HeadersFunc(fn func(ctx context.Context) map[string]string) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, event json.RawMessage) (any, error) {
+			res, err := next(ctx, event)
+			apiRes, ok := asAPIResponse(res)
+			if err != nil || !ok {
+				return res, err
+			}
+
+			add := fn(ctx)
+			if lc, ok := FromContext(ctx); ok && lc.RequestID() != "" {
+				apiRes = mergeHeader(apiRes, RequestIDHeader, lc.RequestID())
+			}
+			for k, v := range add {
+				apiRes = mergeHeader(apiRes, k, v)
+			}
+			return apiRes, nil
+		}
+	}
+}
+
+// mergeHeader sets a header unless res already has it.
+func mergeHeader(res APIResponse, key, value string) APIResponse {
+	if _, ok := header(res.Headers, key); ok {
+		return res
+	}
+	return res.withHeader(key, value)
+}
@@ -0,0 +1,59 @@
+package rambda
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+)
+
+func TestHeadersMiddleware(t *testing.T) {
+	h := Chain(func(ctx context.Context, event json.RawMessage) (any, error) {
+		res := Text(200, "ok")
+		res.Headers["x-content-type-options"] = "handler"
+		return &res, nil
+	}, HeadersMiddleware(map[string]string{
+		"X-Content-Type-Options":    "nosniff",
+		"Strict-Transport-Security": "max-age=63072000",
+	}))
+
+	ctx := lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{AwsRequestID: "req-1"})
+	res, err := h(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"Content-Type":              "text/plain; charset=utf-8",
+		"x-content-type-options":    "handler",
+		"Strict-Transport-Security": "max-age=63072000",
+		RequestIDHeader:             "req-1",
+	}
+	if got := res.(APIResponse).Headers; !reflect.DeepEqual(got, want) {
+		t.Errorf("headers = %v, want %v", got, want)
+	}
+}
+
+func TestHeadersFunc(t *testing.T) {
+	type ctxKey struct{}
+	mw := HeadersFunc(func(ctx context.Context) map[string]string {
+		return map[string]string{"X-Tenant": ctx.Value(ctxKey{}).(string)}
+	})
+	ctx := context.WithValue(context.Background(), ctxKey{}, "acme")
+
+	h := Chain(func(ctx context.Context, event json.RawMessage) (any, error) {
+		return NoContent(), nil
+	}, mw)
+	res, _ := h(ctx, nil)
+	if got := res.(APIResponse).Headers; !reflect.DeepEqual(got, map[string]string{"X-Tenant": "acme"}) {
+		t.Errorf("headers = %v", got)
+	}
+
+	h = Chain(func(ctx context.Context, event json.RawMessage) (any, error) {
+		return "plain", nil
+	}, mw)
+	if res, _ := h(ctx, nil); res != "plain" {
+		t.Errorf("non-API result = %v, want it unchanged", res)
+	}
+}
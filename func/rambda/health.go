@@ -0,0 +1,123 @@
+package rambda
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const defaultCheckTimeout = 2 * time.Second
+
+// Health check statuses used in HealthReport.
+const (
+	HealthOK    = "ok"
+	HealthError = "error"
+)
+
+// HealthReport is the result of running every check of a HealthCheck.
+type HealthReport struct {
+	// Status is HealthOK when every check passed and HealthError otherwise.
+	Status string `json:"status"`
+	// Checks maps each check name to HealthOK or its error message.
+	Checks map[string]string `json:"checks"`
+}
+
+// HealthCheck is a registry of dependency checks for synthetic monitoring.
+type HealthCheck struct {
+	mu      sync.RWMutex
+	checks  map[string]func(ctx context.Context) error
+	timeout time.Duration
+}
+
+// NewHealthCheck returns an empty registry. Use WithCheckTimeout to bound each check.
+func NewHealthCheck(opts ...Option) *HealthCheck {
+	return &HealthCheck{
+		checks:  make(map[string]func(ctx context.Context) error),
+		timeout: newOptions(opts).checkTimeout,
+	}
+}
+
+// Register adds a check called name, replacing any previous one.
+func (h *HealthCheck) Register(name string, check func(ctx context.Context) error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checks[name] = check
+}
+
+// Run runs all checks concurrently. A check that does not finish within the timeout is
+// reported as failed without waiting for it.
+func (h *HealthCheck) Run(ctx context.Context) HealthReport {
+	h.mu.RLock()
+	checks := make(map[string]func(ctx context.Context) error, len(h.checks))
+	for name, check := range h.checks {
+		checks[name] = check
+	}
+	h.mu.RUnlock()
+
+	report := HealthReport{Status: HealthOK, Checks: make(map[string]string, len(checks))}
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for name, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result := HealthOK
+			if err := h.runCheck(ctx, check); err != nil {
+				result = err.Error()
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[name] = result
+			if result != HealthOK {
+				report.Status = HealthError
+			}
+		}()
+	}
+	wg.Wait()
+	return report
+}
+
+func (h *HealthCheck) runCheck(ctx context.Context, check func(ctx context.Context) error) error {
+	if h.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.timeout)
+		defer cancel()
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- safeCall(func() error { return check(ctx) })
+	}()
+	// コンテキストを無視するチェックがあっても報告は遅らせない
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Middleware answers {"healthcheck": true} events with the report of Run, as a 200
+// response when every check passed and a 503 otherwise. The inner handler only runs
+// for other events.
+func (h *HealthCheck) Middleware() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, event json.RawMessage) (any, error) {
+			var probe struct {
+				HealthCheck bool `json:"healthcheck"`
+			}
+			if unmarshal(event, &probe) != nil || !probe.HealthCheck {
+				return next(ctx, event)
+			}
+			report := h.Run(ctx)
+			status := http.StatusOK
+			if report.Status != HealthOK {
+				status = http.StatusServiceUnavailable
+			}
+			return JSON(status, report), nil
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package rambda
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestHealthCheckMiddleware(t *testing.T) {
+	hc := NewHealthCheck(WithCheckTimeout(20 * time.Millisecond))
+	hc.Register("db", func(ctx context.Context) error { return nil })
+
+	calls := 0
+	h := Chain(func(ctx context.Context, event json.RawMessage) (any, error) {
+		calls++
+		return "business", nil
+	}, hc.Middleware())
+
+	res, err := h(context.Background(), json.RawMessage(`{"healthcheck":true}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := res.(APIResponse); r.StatusCode != http.StatusOK || r.Body != `{"status":"ok","checks":{"db":"ok"}}` {
+		t.Errorf("healthy response = %d %s", r.StatusCode, r.Body)
+	}
+	if calls != 0 {
+		t.Error("inner handler should not run for health checks")
+	}
+
+	if res, _ := h(context.Background(), json.RawMessage(`{"id":1}`)); res != "business" {
+		t.Errorf("regular event = %v", res)
+	}
+
+	hc.Register("queue", func(ctx context.Context) error { return errors.New("unreachable") })
+	hc.Register("hung", func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	})
+	start := time.Now()
+	res, _ = h(context.Background(), json.RawMessage(`{"healthcheck":true}`))
+	if time.Since(start) > 500*time.Millisecond {
+		t.Error("a hung check should not delay the report past its timeout")
+	}
+	r := res.(APIResponse)
+	var report HealthReport
+	if err := json.Unmarshal([]byte(r.Body), &report); err != nil {
+		t.Fatal(err)
+	}
+	want := HealthReport{Status: HealthError, Checks: map[string]string{
+		"db":    HealthOK,
+		"queue": "unreachable",
+		"hung":  context.DeadlineExceeded.Error(),
+	}}
+	if r.StatusCode != http.StatusServiceUnavailable || !reflect.DeepEqual(report, want) {
+		t.Errorf("unhealthy response = %d %+v", r.StatusCode, report)
+	}
+}
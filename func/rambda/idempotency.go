@@ -0,0 +1,171 @@
+package rambda
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// ErrIdempotencyInProgress is returned when another invocation is already handling the same event.
+var ErrIdempotencyInProgress = errors.New("rambda: idempotent request already in progress")
+
+// DynamoDBClient is the subset of the DynamoDB API used by IdempotencyMiddleware.
+type DynamoDBClient interface {
+	GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error)
+	PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+	DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error)
+}
+
+const (
+	idempotencyStatusInProgress = "INPROGRESS"
+	idempotencyStatusCompleted  = "COMPLETED"
+
+	defaultIdempotencyTTL = time.Hour
+)
+
+// IdempotencyMiddleware runs the inner handler at most once per event key.
+//
+// Records live in table, keyed by the string attribute "id". "expiration" holds the
+// record's expiry in Unix seconds and should be configured as the table's TTL attribute.
+// The first invocation claims the key with a conditional put, runs the handler and
+// stores the result for the duration set with WithTTL (one hour by default). Later invocations
+// with the same key get the stored result without running the handler, and invocations
+// that race an unfinished one fail with ErrIdempotencyInProgress. If the handler fails,
+// the claim is released so that a retry can run it again.
+//
+// By default the key is a SHA-256 hash of the event's "body" field, as found in API Gateway
+// events, or of the whole event when it has none. Use WithIdempotencyKey to choose another one.
+func IdempotencyMiddleware(table string, client DynamoDBClient, opts ...Option) Middleware {
+	o := newOptions(opts)
+	keyFn := o.idempotencyKey
+	if keyFn == nil {
+		keyFn = defaultIdempotencyKey
+	}
+	ttl := o.ttl
+	if ttl <= 0 {
+		ttl = defaultIdempotencyTTL
+	}
+
+	return func(next Handler) Handler {
+		return func(ctx context.Context, event json.RawMessage) (any, error) {
+			key, err := keyFn(event)
+			if err != nil {
+				return nil, fmt.Errorf("rambda: idempotency key: %w", err)
+			}
+
+			now := time.Now()
+			// 処理中のまま落ちた場合に備えて、処理中の記録は呼び出しの期限で失効させる
+			inProgressUntil := now.Add(ttl)
+			if deadline, ok := ctx.Deadline(); ok {
+				inProgressUntil = deadline
+			}
+			_, err = client.PutItem(ctx, &dynamodb.PutItemInput{
+				TableName: aws.String(table),
+				Item: map[string]types.AttributeValue{
+					"id":                 &types.AttributeValueMemberS{Value: key},
+					"status":             &types.AttributeValueMemberS{Value: idempotencyStatusInProgress},
+					"expiration":         unixAttr(now.Add(ttl)),
+					"in_progress_expiry": unixAttr(inProgressUntil),
+				},
+				ConditionExpression: aws.String("attribute_not_exists(id) OR expiration < :now OR (#status = :in_progress AND in_progress_expiry < :now)"),
+				ExpressionAttributeNames: map[string]string{
+					"#status": "status",
+				},
+				ExpressionAttributeValues: map[string]types.AttributeValue{
+					":now":         unixAttr(now),
+					":in_progress": &types.AttributeValueMemberS{Value: idempotencyStatusInProgress},
+				},
+			})
+			var conditionFailed *types.ConditionalCheckFailedException
+			if errors.As(err, &conditionFailed) {
+				return storedResult(ctx, client, table, key)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("rambda: claim idempotency key: %w", err)
+			}
+
+			res, err := next(ctx, event)
+			if err != nil {
+				_, _ = client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+					TableName: aws.String(table),
+					Key:       map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: key}},
+				})
+				return res, err
+			}
+
+			data, err := encodeResponse(res)
+			if err != nil {
+				return nil, fmt.Errorf("rambda: encode idempotent result: %w", err)
+			}
+			item := map[string]types.AttributeValue{
+				"id":         &types.AttributeValueMemberS{Value: key},
+				"status":     &types.AttributeValueMemberS{Value: idempotencyStatusCompleted},
+				"data":       &types.AttributeValueMemberS{Value: string(data)},
+				"expiration": unixAttr(time.Now().Add(ttl)),
+			}
+			// 再生時に CORS などの外側のミドルウェアが APIResponse として扱えるよう型を残す
+			if _, ok := asAPIResponse(res); ok {
+				item["api_response"] = &types.AttributeValueMemberBOOL{Value: true}
+			}
+			_, err = client.PutItem(ctx, &dynamodb.PutItemInput{
+				TableName: aws.String(table),
+				Item:      item,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("rambda: store idempotent result: %w", err)
+			}
+			return res, nil
+		}
+	}
+}
+
+// storedResult returns the result recorded for key by an earlier invocation.
+// Results that were an APIResponse are decoded back into one.
+func storedResult(ctx context.Context, client DynamoDBClient, table, key string) (any, error) {
+	out, err := client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(table),
+		Key:            map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: key}},
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("rambda: read idempotency record: %w", err)
+	}
+	status, _ := out.Item["status"].(*types.AttributeValueMemberS)
+	data, _ := out.Item["data"].(*types.AttributeValueMemberS)
+	if status == nil || status.Value != idempotencyStatusCompleted || data == nil {
+		return nil, ErrIdempotencyInProgress
+	}
+	if isAPI, _ := out.Item["api_response"].(*types.AttributeValueMemberBOOL); isAPI != nil && isAPI.Value {
+		var res APIResponse
+		if err := unmarshal([]byte(data.Value), &res); err != nil {
+			return nil, fmt.Errorf("rambda: decode idempotent result: %w", err)
+		}
+		return res, nil
+	}
+	return json.RawMessage(data.Value), nil
+}
+
+func defaultIdempotencyKey(event json.RawMessage) (string, error) {
+	payload := []byte(event)
+	var withBody struct {
+		Body *string `json:"body"`
+	}
+	if err := unmarshal(event, &withBody); err == nil && withBody.Body != nil {
+		payload = []byte(*withBody.Body)
+	}
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func unixAttr(t time.Time) types.AttributeValue {
+	return &types.AttributeValueMemberN{Value: strconv.FormatInt(t.Unix(), 10)}
+}
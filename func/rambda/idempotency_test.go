@@ -0,0 +1,167 @@
+package rambda
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// fakeIdempotencyTable evaluates the claim condition used by IdempotencyMiddleware in memory.
+type fakeIdempotencyTable struct {
+	mu    sync.Mutex
+	items map[string]map[string]types.AttributeValue
+}
+
+func newFakeIdempotencyTable() *fakeIdempotencyTable {
+	return &fakeIdempotencyTable{items: make(map[string]map[string]types.AttributeValue)}
+}
+
+func attrInt(av types.AttributeValue) int64 {
+	n, _ := strconv.ParseInt(av.(*types.AttributeValueMemberN).Value, 10, 64)
+	return n
+}
+
+func (f *fakeIdempotencyTable) GetItem(ctx context.Context, in *dynamodb.GetItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return &dynamodb.GetItemOutput{Item: f.items[in.Key["id"].(*types.AttributeValueMemberS).Value]}, nil
+}
+
+func (f *fakeIdempotencyTable) PutItem(ctx context.Context, in *dynamodb.PutItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := in.Item["id"].(*types.AttributeValueMemberS).Value
+	if existing, ok := f.items[key]; ok && in.ConditionExpression != nil {
+		now := attrInt(in.ExpressionAttributeValues[":now"])
+		inProgress := existing["status"].(*types.AttributeValueMemberS).Value == idempotencyStatusInProgress
+		if attrInt(existing["expiration"]) >= now && !(inProgress && attrInt(existing["in_progress_expiry"]) < now) {
+			return nil, &types.ConditionalCheckFailedException{Message: aws.String("exists")}
+		}
+	}
+	f.items[key] = in.Item
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func (f *fakeIdempotencyTable) DeleteItem(ctx context.Context, in *dynamodb.DeleteItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.items, in.Key["id"].(*types.AttributeValueMemberS).Value)
+	return &dynamodb.DeleteItemOutput{}, nil
+}
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	table := newFakeIdempotencyTable()
+	calls := 0
+	h := Chain(func(ctx context.Context, event json.RawMessage) (any, error) {
+		calls++
+		return map[string]int{"calls": calls}, nil
+	}, IdempotencyMiddleware("idempotency", table))
+
+	event := json.RawMessage(`{"body":"{\"order\":1}","requestContext":{"requestId":"a"}}`)
+	first, err := h(context.Background(), event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// requestId が違っても body が同じなら同じリクエストとみなす
+	second, err := h(context.Background(), json.RawMessage(`{"body":"{\"order\":1}","requestContext":{"requestId":"b"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 {
+		t.Errorf("handler ran %d times, want 1", calls)
+	}
+	b1, _ := json.Marshal(first)
+	b2, _ := json.Marshal(second)
+	if string(b1) != string(b2) {
+		t.Errorf("replayed %s, want %s", b2, b1)
+	}
+}
+
+func TestIdempotencyMiddlewareReplaysAPIResponse(t *testing.T) {
+	table := newFakeIdempotencyTable()
+	calls := 0
+	h := Chain(func(ctx context.Context, event json.RawMessage) (any, error) {
+		calls++
+		return JSON(http.StatusCreated, map[string]int{"order": 1}), nil
+	}, HeadersMiddleware(map[string]string{"X-Content-Type-Options": "nosniff"}), IdempotencyMiddleware("idempotency", table))
+
+	event := json.RawMessage(`{"body":"{\"order\":1}"}`)
+	if _, err := h(context.Background(), event); err != nil {
+		t.Fatal(err)
+	}
+	res, err := h(context.Background(), event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 {
+		t.Errorf("handler ran %d times, want 1", calls)
+	}
+	apiRes, ok := res.(APIResponse)
+	if !ok {
+		t.Fatalf("replayed %T, want APIResponse", res)
+	}
+	if apiRes.StatusCode != http.StatusCreated || apiRes.Body != `{"order":1}` {
+		t.Errorf("replayed %+v", apiRes)
+	}
+	if got := apiRes.Headers["X-Content-Type-Options"]; got != "nosniff" {
+		t.Errorf("X-Content-Type-Options = %q, want nosniff", got)
+	}
+}
+
+func TestIdempotencyMiddlewareInProgress(t *testing.T) {
+	table := newFakeIdempotencyTable()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	h := Chain(func(ctx context.Context, event json.RawMessage) (any, error) {
+		close(started)
+		<-release
+		return "done", nil
+	}, IdempotencyMiddleware("idempotency", table, WithIdempotencyKey(func(json.RawMessage) (string, error) {
+		return "fixed", nil
+	})))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	done := make(chan error)
+	go func() {
+		_, err := h(ctx, nil)
+		done <- err
+	}()
+	<-started
+
+	if _, err := h(ctx, nil); !errors.Is(err, ErrIdempotencyInProgress) {
+		t.Errorf("concurrent invocation error = %v, want ErrIdempotencyInProgress", err)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIdempotencyMiddlewareReleasesOnError(t *testing.T) {
+	table := newFakeIdempotencyTable()
+	calls := 0
+	h := Chain(func(ctx context.Context, event json.RawMessage) (any, error) {
+		calls++
+		if calls == 1 {
+			return nil, errors.New("transient")
+		}
+		return "ok", nil
+	}, IdempotencyMiddleware("idempotency", table))
+
+	if _, err := h(context.Background(), json.RawMessage(`{"id":1}`)); err == nil {
+		t.Fatal("expected first call to fail")
+	}
+	if res, err := h(context.Background(), json.RawMessage(`{"id":1}`)); err != nil || res != "ok" {
+		t.Errorf("retry = (%v, %v), want ok", res, err)
+	}
+}
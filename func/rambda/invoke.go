@@ -0,0 +1,54 @@
+package rambda
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+)
+
+// defaultTestTimeout matches the default timeout of a Lambda function.
+const defaultTestTimeout = 3 * time.Second
+
+// NewTestContext returns a context carrying synthetic Lambda metadata, for calling handlers in tests.
+// The request ID and deadline can be set with WithRequestID and WithDeadline;
+// by default a random ID and a deadline 3 seconds from now are used.
+func NewTestContext(opts ...Option) (context.Context, context.CancelFunc) {
+	o := newOptions(opts)
+	requestID := o.requestID
+	if requestID == "" {
+		requestID = newUUID()
+	}
+	deadline := o.deadline
+	if deadline.IsZero() {
+		deadline = time.Now().Add(defaultTestTimeout)
+	}
+
+	ctx := lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{
+		AwsRequestID:       requestID,
+		InvokedFunctionArn: "arn:aws:lambda:us-east-1:123456789012:function:test",
+	})
+	return context.WithDeadline(ctx, deadline)
+}
+
+// Invoke runs handler in-process with payload as the event, under a context built by
+// NewTestContext from opts, and returns the encoded response.
+// handler may be anything lambda.Start accepts, including a Handler or a *Router,
+// so the whole decode, handle and encode path is exercised without deploying.
+func Invoke(handler any, payload []byte, opts ...Option) ([]byte, error) {
+	ctx, cancel := NewTestContext(opts...)
+	defer cancel()
+	return lambda.NewHandler(handler).Invoke(ctx, payload)
+}
+
+// newUUID returns a random version 4 UUID.
+func newUUID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
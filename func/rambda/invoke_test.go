@@ -0,0 +1,54 @@
+package rambda
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestInvoke(t *testing.T) {
+	deadline := time.Now().Add(time.Minute)
+	h := Handler(func(ctx context.Context, event json.RawMessage) (any, error) {
+		lc, ok := FromContext(ctx)
+		if !ok {
+			t.Fatal("no Lambda metadata in context")
+		}
+		if lc.RequestID() != "req-42" || !lc.Deadline().Equal(deadline) {
+			t.Errorf("context = %+v", lc)
+		}
+		return Typed(greet)(ctx, event)
+	})
+
+	b, err := Invoke(h, []byte(`{"name":"test"}`), WithRequestID("req-42"), WithDeadline(deadline))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{"message":"Hello, test"}` {
+		t.Errorf("Invoke() = %s", b)
+	}
+}
+
+func TestInvokePlainFunction(t *testing.T) {
+	b, err := Invoke(greet, []byte(`{"name":"func"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{"message":"Hello, func"}` {
+		t.Errorf("Invoke() = %s", b)
+	}
+}
+
+func TestNewTestContextDefaults(t *testing.T) {
+	ctx, cancel := NewTestContext()
+	defer cancel()
+
+	lc, _ := FromContext(ctx)
+	if !regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`).MatchString(lc.RequestID()) {
+		t.Errorf("RequestID() = %q, want a UUID", lc.RequestID())
+	}
+	if r := lc.RemainingTime(); r <= 0 || r > defaultTestTimeout {
+		t.Errorf("RemainingTime() = %s", r)
+	}
+}
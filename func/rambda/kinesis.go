@@ -0,0 +1,77 @@
+package rambda
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// KinesisRecord is a Kinesis stream record with its data already base64-decoded.
+type KinesisRecord struct {
+	EventID        string
+	PartitionKey   string
+	SequenceNumber string
+
+	data []byte
+}
+
+// Data returns the decoded record payload.
+func (r KinesisRecord) Data() []byte {
+	return r.data
+}
+
+// kinesisEvent mirrors events.KinesisEvent but keeps data encoded, so that one
+// malformed record does not make the whole batch undecodable.
+type kinesisEvent struct {
+	Records []struct {
+		EventID string `json:"eventID"`
+		Kinesis struct {
+			Data           string `json:"data"`
+			PartitionKey   string `json:"partitionKey"`
+			SequenceNumber string `json:"sequenceNumber"`
+		} `json:"kinesis"`
+	} `json:"Records"`
+}
+
+// KinesisHandler adapts a per-record function into a Handler for Kinesis streams.
+//
+// The returned events.KinesisEventResponse lists the sequence number of every record
+// whose data could not be decoded or for which fn returned an error or panicked, so that
+// with ReportBatchItemFailures enabled the stream is retried from the first of them.
+// Use WithConcurrency to process records in parallel.
+func KinesisHandler(fn func(ctx context.Context, rec KinesisRecord) error, opts ...Option) Handler {
+	o := newOptions(opts)
+	return func(ctx context.Context, event json.RawMessage) (any, error) {
+		var streamEvent kinesisEvent
+		if err := unmarshal(event, &streamEvent); err != nil {
+			return nil, newDecodeError(&streamEvent, event, err)
+		}
+
+		failed := processBatch(len(streamEvent.Records), o.concurrency, func(i int) error {
+			r := streamEvent.Records[i]
+			data, err := base64.StdEncoding.DecodeString(r.Kinesis.Data)
+			if err != nil {
+				return fmt.Errorf("rambda: decode kinesis record %s: %w", r.Kinesis.SequenceNumber, err)
+			}
+			return fn(ctx, KinesisRecord{
+				EventID:        r.EventID,
+				PartitionKey:   r.Kinesis.PartitionKey,
+				SequenceNumber: r.Kinesis.SequenceNumber,
+				data:           data,
+			})
+		})
+
+		res := events.KinesisEventResponse{
+			BatchItemFailures: make([]events.KinesisBatchItemFailure, 0, len(failed)),
+		}
+		for _, i := range failed {
+			res.BatchItemFailures = append(res.BatchItemFailures, events.KinesisBatchItemFailure{
+				ItemIdentifier: streamEvent.Records[i].Kinesis.SequenceNumber,
+			})
+		}
+		return res, nil
+	}
+}
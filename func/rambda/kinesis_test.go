@@ -0,0 +1,47 @@
+package rambda
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func kinesisEventPayload(data ...string) json.RawMessage {
+	records := make([]string, len(data))
+	for i, d := range data {
+		records[i] = fmt.Sprintf(`{"eventID":"shard-0:%d","eventSource":"aws:kinesis","kinesis":{"partitionKey":"pk-%d","sequenceNumber":"%d","data":%q}}`, i, i, i, d)
+	}
+	return json.RawMessage(`{"Records":[` + strings.Join(records, ",") + `]}`)
+}
+
+func TestKinesisHandler(t *testing.T) {
+	var got []string
+	h := KinesisHandler(func(ctx context.Context, rec KinesisRecord) error {
+		got = append(got, rec.PartitionKey+"="+string(rec.Data()))
+		if string(rec.Data()) == "bad" {
+			return errors.New("failed")
+		}
+		return nil
+	})
+
+	enc := base64.StdEncoding.EncodeToString
+	res, err := h(context.Background(), kinesisEventPayload(enc([]byte("one")), "not base64!", enc([]byte("bad"))))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"pk-0=one", "pk-2=bad"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("records = %v, want %v", got, want)
+	}
+	want := []events.KinesisBatchItemFailure{{ItemIdentifier: "1"}, {ItemIdentifier: "2"}}
+	if failures := res.(events.KinesisEventResponse).BatchItemFailures; !reflect.DeepEqual(failures, want) {
+		t.Errorf("failures = %v, want %v", failures, want)
+	}
+}
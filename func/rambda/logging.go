@@ -0,0 +1,43 @@
+package rambda
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"time"
+)
+
+// LoggingMiddleware logs one JSON line when an invocation starts and one when it ends.
+// If logger is nil, a JSON logger writing to stdout is used.
+// Lines include the correlation ID when one is in the context, see CorrelationIDMiddleware.
+func LoggingMiddleware(logger *slog.Logger) Middleware {
+	if logger == nil {
+		logger = slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	}
+	return func(next Handler) Handler {
+		return func(ctx context.Context, event json.RawMessage) (any, error) {
+			lc, _ := FromContext(ctx)
+			log := logger.With(slog.String("request_id", lc.RequestID()))
+			if id := CorrelationIDFromContext(ctx); id != "" {
+				log = log.With(slog.String("correlation_id", id))
+			}
+
+			log.InfoContext(ctx, "invocation started")
+			start := time.Now()
+
+			res, err := next(ctx, event)
+
+			attrs := []any{
+				slog.Duration("duration", time.Since(start)),
+				slog.Bool("error", err != nil),
+			}
+			if err != nil {
+				log.ErrorContext(ctx, "invocation finished", append(attrs, slog.String("error_message", err.Error()))...)
+			} else {
+				log.InfoContext(ctx, "invocation finished", attrs...)
+			}
+			return res, err
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package rambda
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+)
+
+func TestLoggingMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	wantErr := errors.New("boom")
+
+	h := Chain(func(ctx context.Context, event json.RawMessage) (any, error) {
+		return nil, wantErr
+	}, LoggingMiddleware(logger))
+
+	ctx := lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{AwsRequestID: "req-1"})
+	if _, err := h(ctx, nil); err != wantErr {
+		t.Fatalf("error = %v, want %v", err, wantErr)
+	}
+
+	var lines []map[string]any
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var line map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("log line is not JSON: %s", scanner.Text())
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) != 2 {
+		t.Fatalf("got %d log lines, want 2", len(lines))
+	}
+	if lines[0]["level"] != "INFO" || lines[0]["request_id"] != "req-1" {
+		t.Errorf("start line = %v", lines[0])
+	}
+	end := lines[1]
+	if end["level"] != "ERROR" || end["error"] != true || end["error_message"] != "boom" {
+		t.Errorf("end line = %v", end)
+	}
+	if _, ok := end["duration"]; !ok {
+		t.Errorf("end line has no duration: %v", end)
+	}
+}
@@ -0,0 +1,137 @@
+package rambda
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	// maxMetricsPerDocument is the CloudWatch limit on metrics in one EMF document.
+	maxMetricsPerDocument = 100
+	// maxValuesPerMetric is the CloudWatch limit on values of one metric in one EMF document.
+	maxValuesPerMetric = 100
+	// maxDimensions is the CloudWatch limit on dimensions in one dimension set.
+	maxDimensions = 30
+)
+
+// Metrics buffers custom metrics for one invocation and writes them as EMF documents on Flush.
+// All methods are safe for concurrent use and are no-ops on a nil *Metrics.
+type Metrics struct {
+	namespace string
+
+	mu         sync.Mutex
+	dimensions map[string]string
+	values     []emfValue
+}
+
+// NewMetrics returns an empty Metrics that publishes into namespace.
+func NewMetrics(namespace string) *Metrics {
+	return &Metrics{
+		namespace:  namespace,
+		dimensions: make(map[string]string),
+	}
+}
+
+// Count records value for the metric name with the Count unit.
+func (m *Metrics) Count(name string, value float64) {
+	m.add(emfValue{Name: name, Unit: "Count", Value: value})
+}
+
+// Duration records d for the metric name in milliseconds.
+func (m *Metrics) Duration(name string, d time.Duration) {
+	m.add(emfValue{Name: name, Unit: "Milliseconds", Value: float64(d) / float64(time.Millisecond)})
+}
+
+// Dimension adds a dimension applied to every metric in the flush.
+// Dimensions beyond the CloudWatch limit of 30 are ignored.
+func (m *Metrics) Dimension(k, v string) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.dimensions[k]; !ok && len(m.dimensions) >= maxDimensions {
+		return
+	}
+	m.dimensions[k] = v
+}
+
+func (m *Metrics) add(v emfValue) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.values = append(m.values, v)
+}
+
+// Flush writes the buffered metrics and clears the buffer.
+// At most 100 distinct metrics, each with at most 100 values, go into one EMF line;
+// the rest are split across further lines.
+func (m *Metrics) Flush() error {
+	if m == nil {
+		return nil
+	}
+	m.mu.Lock()
+	values := m.values
+	m.values = nil
+	dimensions := make(map[string]string, len(m.dimensions))
+	for k, v := range m.dimensions {
+		dimensions[k] = v
+	}
+	m.mu.Unlock()
+
+	// 値は出てきた順に、名前の数と値の数の上限に収まる最初のドキュメントへ割り当てる
+	type emfDoc struct {
+		values []emfValue
+		counts map[string]int
+	}
+	var docs []*emfDoc
+	start := make(map[string]int)
+	for _, v := range values {
+		i := start[v.Name]
+		for ; i < len(docs); i++ {
+			n, ok := docs[i].counts[v.Name]
+			if ok && n < maxValuesPerMetric || !ok && len(docs[i].counts) < maxMetricsPerDocument {
+				break
+			}
+		}
+		if i == len(docs) {
+			docs = append(docs, &emfDoc{counts: make(map[string]int)})
+		}
+		start[v.Name] = i
+		docs[i].counts[v.Name]++
+		docs[i].values = append(docs[i].values, v)
+	}
+
+	var errs []error
+	for _, doc := range docs {
+		if err := writeEMF(m.namespace, dimensions, doc.values); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+type metricsKey struct{}
+
+// MetricsFromContext returns the Metrics attached by MetricsMiddleware, or nil.
+func MetricsFromContext(ctx context.Context) *Metrics {
+	m, _ := ctx.Value(metricsKey{}).(*Metrics)
+	return m
+}
+
+// MetricsMiddleware gives every invocation its own Metrics, available through MetricsFromContext,
+// and flushes it when the handler returns, including when it errors or panics.
+func MetricsMiddleware(namespace string) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, event json.RawMessage) (any, error) {
+			m := NewMetrics(namespace)
+			defer func() { _ = m.Flush() }()
+			return next(context.WithValue(ctx, metricsKey{}, m), event)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package rambda
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestMetricsMiddlewareFlushesOnError(t *testing.T) {
+	buf := captureEMF(t)
+
+	h := Chain(func(ctx context.Context, event json.RawMessage) (any, error) {
+		m := MetricsFromContext(ctx)
+		m.Dimension("Service", "orders")
+		m.Count("OrdersPlaced", 1)
+		m.Count("OrdersPlaced", 2)
+		m.Duration("Latency", 1500*time.Microsecond)
+		return nil, errors.New("boom")
+	}, MetricsMiddleware("shop"))
+
+	if _, err := h(context.Background(), nil); err == nil {
+		t.Fatal("expected error")
+	}
+
+	lines := emfLines(t, buf)
+	if len(lines) != 1 {
+		t.Fatalf("got %d EMF lines, want 1", len(lines))
+	}
+	doc := lines[0]
+	if doc["Service"] != "orders" || doc["Latency"] != 1.5 {
+		t.Errorf("doc = %v", doc)
+	}
+	if got := fmt.Sprint(doc["OrdersPlaced"]); got != "[1 2]" {
+		t.Errorf("OrdersPlaced = %s, want [1 2]", got)
+	}
+	directive := doc["_aws"].(map[string]any)["CloudWatchMetrics"].([]any)[0].(map[string]any)
+	if directive["Namespace"] != "shop" || len(directive["Metrics"].([]any)) != 2 {
+		t.Errorf("directive = %v", directive)
+	}
+}
+
+func TestMetricsFlushSplitsDocuments(t *testing.T) {
+	buf := captureEMF(t)
+
+	m := NewMetrics("ns")
+	for i := 0; i < 250; i++ {
+		m.Count(fmt.Sprintf("m%d", i), 1)
+	}
+	if err := m.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := emfLines(t, buf)
+	if len(lines) != 3 {
+		t.Fatalf("got %d EMF lines, want 3", len(lines))
+	}
+	for i, want := range []int{100, 100, 50} {
+		directive := lines[i]["_aws"].(map[string]any)["CloudWatchMetrics"].([]any)[0].(map[string]any)
+		if got := len(directive["Metrics"].([]any)); got != want {
+			t.Errorf("line %d has %d metrics, want %d", i, got, want)
+		}
+	}
+}
+
+func TestMetricsFlushSplitsValues(t *testing.T) {
+	buf := captureEMF(t)
+
+	m := NewMetrics("ns")
+	for i := 0; i < 250; i++ {
+		m.Count("Processed", float64(i))
+	}
+	m.Count("Errors", 1)
+	if err := m.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := emfLines(t, buf)
+	if len(lines) != 3 {
+		t.Fatalf("got %d EMF lines, want 3", len(lines))
+	}
+	total := 0
+	for i, want := range []int{100, 100, 50} {
+		values, _ := lines[i]["Processed"].([]any)
+		if len(values) != want {
+			t.Errorf("line %d has %d Processed values, want %d", i, len(values), want)
+		}
+		total += len(values)
+	}
+	if total != 250 {
+		t.Errorf("wrote %d values, want 250", total)
+	}
+	if lines[0]["Errors"] != 1.0 {
+		t.Errorf("Errors = %v, want it in the first line", lines[0]["Errors"])
+	}
+}
+
+func TestNilMetrics(t *testing.T) {
+	m := MetricsFromContext(context.Background())
+	m.Count("x", 1)
+	m.Dimension("k", "v")
+	if err := m.Flush(); err != nil {
+		t.Errorf("Flush() = %v", err)
+	}
+}
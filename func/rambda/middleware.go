@@ -0,0 +1,58 @@
+package rambda
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// Handler is the raw form of a Lambda handler used throughout this package.
+// It implements lambda.Handler, so it can be passed directly to lambda.Start.
+type Handler func(ctx context.Context, event json.RawMessage) (any, error)
+
+// Invoke implements lambda.Handler, encoding the result with the marshaler set by SetMarshaler.
+// When an envelope was set with SetEnvelope, the result is wrapped with it first.
+// Every call counts as an invocation for IsColdStart.
+func (h Handler) Invoke(ctx context.Context, payload []byte) ([]byte, error) {
+	state := &invocationState{}
+	ctx = context.WithValue(ctx, invocationKey{}, state)
+	recordInvocation(ctx)
+
+	res, err := h(ctx, payload)
+	if err != nil {
+		return nil, err
+	}
+	if env, ok := configuredEnvelope(); ok && !state.wrapped {
+		res = wrapResult(env, res)
+	}
+	return encodeResponse(res)
+}
+
+// invocationState is shared by the layers of one invocation started by Handler.Invoke.
+type invocationState struct {
+	// recorded and cold hold the result of recordInvocation.
+	recorded bool
+	cold     bool
+	// wrapped is set by EnvelopeMiddleware once the result is wrapped.
+	wrapped bool
+}
+
+type invocationKey struct{}
+
+func invocationFromContext(ctx context.Context) *invocationState {
+	state, _ := ctx.Value(invocationKey{}).(*invocationState)
+	return state
+}
+
+// Middleware wraps a Handler with cross-cutting behavior.
+type Middleware func(Handler) Handler
+
+// Chain wraps h with mw so that mw[0] is the outermost layer and runs first.
+//
+// Each middleware calls into the next one on the same goroutine, so when an inner
+// layer panics the deferred calls of every outer layer still run while the panic unwinds.
+func Chain(h Handler, mw ...Middleware) Handler {
+	for i := len(mw) - 1; i >= 0; i-- {
+		h = mw[i](h)
+	}
+	return h
+}
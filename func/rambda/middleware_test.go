@@ -0,0 +1,60 @@
+package rambda
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func recordingMiddleware(name string, calls *[]string) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, event json.RawMessage) (any, error) {
+			*calls = append(*calls, name+":before")
+			defer func() { *calls = append(*calls, name+":after") }()
+			return next(ctx, event)
+		}
+	}
+}
+
+func TestChainOrder(t *testing.T) {
+	var calls []string
+	h := Chain(func(ctx context.Context, event json.RawMessage) (any, error) {
+		calls = append(calls, "handler")
+		return nil, nil
+	}, recordingMiddleware("outer", &calls), recordingMiddleware("inner", &calls))
+
+	if _, err := h(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"outer:before", "inner:before", "handler", "inner:after", "outer:after"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+}
+
+func TestChainPanicRunsDeferredCleanup(t *testing.T) {
+	var calls []string
+	panicking := func(next Handler) Handler {
+		return func(ctx context.Context, event json.RawMessage) (any, error) {
+			panic("boom")
+		}
+	}
+	h := Chain(func(ctx context.Context, event json.RawMessage) (any, error) {
+		return nil, nil
+	}, recordingMiddleware("outer", &calls), recordingMiddleware("inner", &calls), panicking)
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected panic to propagate")
+			}
+		}()
+		_, _ = h(context.Background(), nil)
+	}()
+
+	want := []string{"outer:before", "inner:before", "inner:after", "outer:after"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+}
@@ -0,0 +1,220 @@
+package rambda
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Option configures the handlers and middleware in this package.
+// Each constructor only reads the settings that apply to it.
+type Option func(*options)
+
+type options struct {
+	concurrency int
+	s3Client    S3Client
+	ttl         time.Duration
+	errorMapper ErrorMapper
+
+	compressionThreshold int
+
+	idempotencyKey func(event json.RawMessage) (string, error)
+
+	escapeHTML bool
+	useNumber  bool
+	sortedKeys bool
+
+	requestID string
+	deadline  time.Time
+
+	maxAttempts int
+	baseDelay   time.Duration
+	maxDelay    time.Duration
+	retryIf     func(error) bool
+
+	deadLetterURL string
+	sqsClient     SQSClient
+
+	flushInterval time.Duration
+	contentType   string
+
+	secretsClient SecretsManagerClient
+	ssmClient     SSMClient
+
+	checkTimeout time.Duration
+
+	timeBuffer time.Duration
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		errorMapper: DefaultErrorMapper,
+
+		compressionThreshold: 1024,
+
+		maxAttempts: defaultMaxAttempts,
+		baseDelay:   defaultBaseDelay,
+		maxDelay:    defaultMaxDelay,
+
+		flushInterval: defaultFlushInterval,
+		contentType:   defaultStreamContentType,
+
+		checkTimeout: defaultCheckTimeout,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithConcurrency processes up to n batch items at once.
+// Values of n <= 0 process items serially.
+func WithConcurrency(n int) Option {
+	return func(o *options) {
+		o.concurrency = n
+	}
+}
+
+// WithS3Client sets the client S3Object.Head uses instead of one built from the default AWS config.
+func WithS3Client(client S3Client) Option {
+	return func(o *options) {
+		o.s3Client = client
+	}
+}
+
+// WithTTL expires cached values after d. A zero d keeps them for the container lifetime.
+func WithTTL(d time.Duration) Option {
+	return func(o *options) {
+		o.ttl = d
+	}
+}
+
+// WithErrorMapper sets how API handlers turn errors into responses. The default is DefaultErrorMapper.
+func WithErrorMapper(m ErrorMapper) Option {
+	return func(o *options) {
+		o.errorMapper = m
+	}
+}
+
+// WithCompressionThreshold sets the body size in bytes above which responses are gzipped. The default is 1KB.
+func WithCompressionThreshold(n int) Option {
+	return func(o *options) {
+		o.compressionThreshold = n
+	}
+}
+
+// WithIdempotencyKey sets how IdempotencyMiddleware derives the key of an event.
+func WithIdempotencyKey(fn func(event json.RawMessage) (string, error)) Option {
+	return func(o *options) {
+		o.idempotencyKey = fn
+	}
+}
+
+// WithEscapeHTML controls whether JSONMarshaler escapes <, > and & in strings.
+func WithEscapeHTML(escape bool) Option {
+	return func(o *options) {
+		o.escapeHTML = escape
+	}
+}
+
+// WithUseNumber makes JSONUnmarshaler decode numbers into json.Number instead of float64,
+// preserving their exact text.
+func WithUseNumber() Option {
+	return func(o *options) {
+		o.useNumber = true
+	}
+}
+
+// WithSortedKeys makes JSONMarshaler and JSONCodec sort the keys of every JSON object,
+// including those of structs, so that output is byte-for-byte reproducible.
+func WithSortedKeys() Option {
+	return func(o *options) {
+		o.sortedKeys = true
+	}
+}
+
+// WithRequestID sets the AWS request ID of a synthetic invocation context.
+func WithRequestID(id string) Option {
+	return func(o *options) {
+		o.requestID = id
+	}
+}
+
+// WithDeadline sets the deadline of a synthetic invocation context.
+func WithDeadline(t time.Time) Option {
+	return func(o *options) {
+		o.deadline = t
+	}
+}
+
+// WithMaxAttempts caps how many times Retry calls its function, including the first call.
+func WithMaxAttempts(n int) Option {
+	return func(o *options) {
+		o.maxAttempts = n
+	}
+}
+
+// WithBackoff sets the delay before the first retry and the cap it doubles up to.
+func WithBackoff(base, max time.Duration) Option {
+	return func(o *options) {
+		o.baseDelay = base
+		o.maxDelay = max
+	}
+}
+
+// WithRetryIf sets which errors Retry retries. By default only errors marked with Retryable are.
+func WithRetryIf(fn func(error) bool) Option {
+	return func(o *options) {
+		o.retryIf = fn
+	}
+}
+
+// WithDeadLetter makes SQSHandler send messages that still fail after retrying to the
+// queue at queueURL instead of reporting them as batch item failures.
+func WithDeadLetter(queueURL string, client SQSClient) Option {
+	return func(o *options) {
+		o.deadLetterURL = queueURL
+		o.sqsClient = client
+	}
+}
+
+// WithFlushInterval sets how often StreamHandler flushes buffered output to the client. The default is 100ms.
+func WithFlushInterval(d time.Duration) Option {
+	return func(o *options) {
+		o.flushInterval = d
+	}
+}
+
+// WithContentType sets the Content-Type StreamHandler sends unless the handler sets its own.
+func WithContentType(ct string) Option {
+	return func(o *options) {
+		o.contentType = ct
+	}
+}
+
+// WithSecretsManagerClient sets the client ResolveSecrets uses for Secrets Manager ARNs.
+func WithSecretsManagerClient(client SecretsManagerClient) Option {
+	return func(o *options) {
+		o.secretsClient = client
+	}
+}
+
+// WithSSMClient sets the client ResolveSecrets uses for Parameter Store names.
+func WithSSMClient(client SSMClient) Option {
+	return func(o *options) {
+		o.ssmClient = client
+	}
+}
+
+// WithCheckTimeout bounds how long each health check may run. The default is 2s.
+func WithCheckTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.checkTimeout = d
+	}
+}
+
+// WithTimeBuffer makes SQSHandler stop starting messages d before the invocation deadline.
+func WithTimeBuffer(d time.Duration) Option {
+	return func(o *options) {
+		o.timeBuffer = d
+	}
+}
@@ -0,0 +1,34 @@
+package rambda
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+)
+
+// Parallel fans an event out to all handlers at once and waits for every one of them.
+// The result is a []any with each handler's result in argument order. Errors, including
+// panics as *PanicError, are combined with errors.Join, so errors.Is and errors.As match
+// any of them.
+func Parallel(handlers ...Handler) Handler {
+	return func(ctx context.Context, event json.RawMessage) (any, error) {
+		results := make([]any, len(handlers))
+		errs := make([]error, len(handlers))
+
+		var wg sync.WaitGroup
+		for i, h := range handlers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs[i] = safeCall(func() error {
+					var err error
+					results[i], err = h(ctx, event)
+					return err
+				})
+			}()
+		}
+		wg.Wait()
+		return results, errors.Join(errs...)
+	}
+}
@@ -0,0 +1,44 @@
+package rambda
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParallel(t *testing.T) {
+	errA := errors.New("a failed")
+	var finished atomic.Int32
+	h := Parallel(
+		func(ctx context.Context, event json.RawMessage) (any, error) {
+			return nil, errA
+		},
+		func(ctx context.Context, event json.RawMessage) (any, error) {
+			time.Sleep(10 * time.Millisecond)
+			finished.Add(1)
+			return string(event), nil
+		},
+		func(ctx context.Context, event json.RawMessage) (any, error) {
+			return nil, &ValidationError{}
+		},
+	)
+
+	res, err := h(context.Background(), json.RawMessage(`"evt"`))
+	if finished.Load() != 1 {
+		t.Error("Parallel should wait for slow handlers after an early failure")
+	}
+	if !errors.Is(err, errA) {
+		t.Errorf("error %v should match the first handler's error", err)
+	}
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Errorf("error %v should match the third handler's error", err)
+	}
+	if want := []any{nil, `"evt"`, nil}; !reflect.DeepEqual(res, want) {
+		t.Errorf("results = %v, want %v", res, want)
+	}
+}
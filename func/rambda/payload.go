@@ -0,0 +1,35 @@
+package rambda
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// PayloadTooLargeError is returned by MaxPayloadMiddleware for events over its limit.
+// DefaultErrorMapper maps it to 413.
+type PayloadTooLargeError struct {
+	Size  int
+	Limit int
+}
+
+func (e *PayloadTooLargeError) Error() string {
+	return fmt.Sprintf("rambda: payload of %d bytes exceeds the limit of %d bytes", e.Size, e.Limit)
+}
+
+// MaxPayloadMiddleware rejects events larger than limit bytes with a *PayloadTooLargeError
+// before the inner handler decodes them. A limit <= 0 disables the check.
+// Chain ErrorMiddleware outside it to answer API Gateway callers with a 413.
+func MaxPayloadMiddleware(limit int) Middleware {
+	return func(next Handler) Handler {
+		if limit <= 0 {
+			return next
+		}
+		return func(ctx context.Context, event json.RawMessage) (any, error) {
+			if len(event) > limit {
+				return nil, &PayloadTooLargeError{Size: len(event), Limit: limit}
+			}
+			return next(ctx, event)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package rambda
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestMaxPayloadMiddleware(t *testing.T) {
+	calls := 0
+	inner := func(ctx context.Context, event json.RawMessage) (any, error) {
+		calls++
+		return nil, nil
+	}
+
+	h := Chain(inner, MaxPayloadMiddleware(8))
+	if _, err := h(context.Background(), json.RawMessage(`"12345"`)); err != nil || calls != 1 {
+		t.Fatalf("small payload: err = %v, calls = %d", err, calls)
+	}
+	_, err := h(context.Background(), json.RawMessage(`"123456789"`))
+	var pe *PayloadTooLargeError
+	if !errors.As(err, &pe) || pe.Size != 11 || pe.Limit != 8 || calls != 1 {
+		t.Errorf("large payload: err = %v, calls = %d", err, calls)
+	}
+	if res := DefaultErrorMapper.MapError(err); res.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Errorf("mapped status = %d, want 413", res.StatusCode)
+	}
+
+	h = Chain(inner, MaxPayloadMiddleware(0))
+	if _, err := h(context.Background(), json.RawMessage(`"123456789"`)); err != nil {
+		t.Errorf("limit 0 should be unlimited, got %v", err)
+	}
+}
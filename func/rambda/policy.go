@@ -0,0 +1,233 @@
+package rambda
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// ErrInvalidPolicy is returned by WithPolicy for policies that cannot apply to their source.
+var ErrInvalidPolicy = errors.New("rambda: invalid policy")
+
+// Policy declares how a Dispatcher handles failures of one event source.
+type Policy struct {
+	// MaxRetries is how many times a failed event is retried in place.
+	MaxRetries int
+	// Backoff and MaxBackoff bound the jittered exponential delay between retries,
+	// as with WithBackoff. Zero values use the Retry defaults.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// DeadLetterQueue is the URL of an SQS queue that receives SQS messages still
+	// failing after the retries, using SQSClient. Only valid for SourceSQS.
+	DeadLetterQueue string
+	SQSClient       SQSClient
+}
+
+// WithPolicy attaches p to the handler for source.
+//
+// For SQS and Kinesis, whose handlers report partial batch failures, only the failed
+// records are retried and the final response lists those that still fail; SQS messages
+// forwarded to the dead-letter queue are left out of it. For other sources the whole
+// event is retried when the handler returns an error.
+func (d *Dispatcher) WithPolicy(source EventSource, p Policy) error {
+	if p.MaxRetries < 0 {
+		return fmt.Errorf("%w for source %q: negative MaxRetries", ErrInvalidPolicy, source)
+	}
+	if p.DeadLetterQueue != "" {
+		if source != SourceSQS {
+			return fmt.Errorf("%w for source %q: dead-letter forwarding is only supported for %q", ErrInvalidPolicy, source, SourceSQS)
+		}
+		if p.SQSClient == nil {
+			return fmt.Errorf("%w for source %q: DeadLetterQueue requires an SQSClient", ErrInvalidPolicy, source)
+		}
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.policies[source] = p
+	return nil
+}
+
+// WithPolicy attaches p to the handler for source on DefaultDispatcher.
+func WithPolicy(source EventSource, p Policy) error {
+	return DefaultDispatcher.WithPolicy(source, p)
+}
+
+func (p Policy) retryOptions() []Option {
+	opts := []Option{
+		WithMaxAttempts(p.MaxRetries + 1),
+		WithRetryIf(func(error) bool { return true }),
+	}
+	if p.Backoff > 0 || p.MaxBackoff > 0 {
+		opts = append(opts, WithBackoff(p.Backoff, max(p.Backoff, p.MaxBackoff)))
+	}
+	return opts
+}
+
+func (p Policy) apply(ctx context.Context, source EventSource, h Handler, event json.RawMessage) (any, error) {
+	switch source {
+	case SourceSQS, SourceKinesis:
+		return p.applyBatch(ctx, source, h, event)
+	}
+	var res any
+	err := Retry(ctx, func() error {
+		var err error
+		res, err = h(ctx, event)
+		return err
+	}, p.retryOptions()...)
+	return res, err
+}
+
+// errBatchItemsFailed makes Retry go on while records of a batch are still failing.
+var errBatchItemsFailed = errors.New("rambda: batch items failed")
+
+func (p Policy) applyBatch(ctx context.Context, source EventSource, h Handler, event json.RawMessage) (any, error) {
+	var envelope map[string]json.RawMessage
+	var batch struct {
+		Records []json.RawMessage `json:"Records"`
+	}
+	if err := unmarshal(event, &envelope); err != nil {
+		return nil, newDecodeError(&envelope, event, err)
+	}
+	if err := unmarshal(event, &batch); err != nil {
+		return nil, newDecodeError(&batch, event, err)
+	}
+
+	records := batch.Records
+	recorded := &batchErrors{errs: map[string]error{}}
+	bctx := context.WithValue(ctx, batchErrorsKey{}, recorded)
+	var handlerErr error
+	err := Retry(ctx, func() error {
+		payload := event
+		if len(records) != len(batch.Records) {
+			// 失敗したレコードだけを元のイベントに詰め直して再試行する
+			envelope["Records"], _ = marshal(records)
+			payload, _ = marshal(envelope)
+		}
+		res, err := h(bctx, payload)
+		if err != nil {
+			handlerErr = err
+			return err
+		}
+		failed := batchFailureIDs(res)
+		handlerErr = nil
+		records = slices.DeleteFunc(records, func(r json.RawMessage) bool {
+			return !slices.Contains(failed, recordID(source, r))
+		})
+		if len(records) > 0 {
+			return errBatchItemsFailed
+		}
+		return nil
+	}, p.retryOptions()...)
+	if handlerErr != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for _, r := range records {
+		id := recordID(source, r)
+		cause := recorded.get(id)
+		if cause == nil {
+			cause = errBatchItemsFailed
+		}
+		if err == nil || !p.forward(ctx, r, cause) {
+			ids = append(ids, id)
+		}
+	}
+	if source == SourceKinesis {
+		res := events.KinesisEventResponse{BatchItemFailures: make([]events.KinesisBatchItemFailure, 0, len(ids))}
+		for _, id := range ids {
+			res.BatchItemFailures = append(res.BatchItemFailures, events.KinesisBatchItemFailure{ItemIdentifier: id})
+		}
+		return res, nil
+	}
+	res := events.SQSEventResponse{BatchItemFailures: make([]events.SQSBatchItemFailure, 0, len(ids))}
+	for _, id := range ids {
+		res.BatchItemFailures = append(res.BatchItemFailures, events.SQSBatchItemFailure{ItemIdentifier: id})
+	}
+	return res, nil
+}
+
+// batchErrors collects the last error reported for each record of a batch, so that records
+// forwarded to the dead-letter queue carry the error the handler returned for them.
+type batchErrors struct {
+	mu   sync.Mutex
+	errs map[string]error
+}
+
+type batchErrorsKey struct{}
+
+func (b *batchErrors) get(id string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.errs[id]
+}
+
+// recordBatchError reports the error of the record with id to the batch policy running
+// the handler, if any.
+func recordBatchError(ctx context.Context, id string, err error) {
+	b, ok := ctx.Value(batchErrorsKey{}).(*batchErrors)
+	if !ok {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.errs[id] = err
+}
+
+// forward sends an SQS record to the dead-letter queue and reports whether it was sent.
+func (p Policy) forward(ctx context.Context, record json.RawMessage, cause error) bool {
+	if p.DeadLetterQueue == "" {
+		return false
+	}
+	var msg events.SQSMessage
+	if err := unmarshal(record, &msg); err != nil {
+		return false
+	}
+	o := newOptions([]Option{WithDeadLetter(p.DeadLetterQueue, p.SQSClient)})
+	return sendDeadLetter(ctx, o, msg, cause) == nil
+}
+
+// batchFailureIDs extracts the item identifiers of a partial batch response of any type.
+func batchFailureIDs(res any) []string {
+	if res == nil {
+		return nil
+	}
+	b, err := marshal(res)
+	if err != nil {
+		return nil
+	}
+	var resp struct {
+		BatchItemFailures []struct {
+			ItemIdentifier string `json:"itemIdentifier"`
+		} `json:"batchItemFailures"`
+	}
+	if unmarshal(b, &resp) != nil {
+		return nil
+	}
+	ids := make([]string, len(resp.BatchItemFailures))
+	for i, f := range resp.BatchItemFailures {
+		ids[i] = f.ItemIdentifier
+	}
+	return ids
+}
+
+// recordID returns the identifier partial batch responses use for a record of source.
+func recordID(source EventSource, record json.RawMessage) string {
+	var r struct {
+		MessageID string `json:"messageId"`
+		Kinesis   struct {
+			SequenceNumber string `json:"sequenceNumber"`
+		} `json:"kinesis"`
+	}
+	_ = unmarshal(record, &r)
+	if source == SourceKinesis {
+		return r.Kinesis.SequenceNumber
+	}
+	return r.MessageID
+}
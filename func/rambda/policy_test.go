@@ -0,0 +1,122 @@
+package rambda
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+func TestDispatcherPolicySQS(t *testing.T) {
+	attempts := map[string]int{}
+	d := NewDispatcher()
+	d.Handle(SourceSQS, SQSHandler(func(ctx context.Context, msg events.SQSMessage) error {
+		attempts[msg.Body]++
+		switch {
+		case msg.Body == "flaky" && attempts[msg.Body] < 2:
+			return errors.New("timeout")
+		case msg.Body == "poison":
+			return errors.New("cannot parse")
+		}
+		return nil
+	}))
+	client := &fakeSQS{}
+	err := d.WithPolicy(SourceSQS, Policy{
+		MaxRetries:      2,
+		Backoff:         time.Millisecond,
+		DeadLetterQueue: "https://sqs/dlq",
+		SQSClient:       client,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := d.Dispatch(context.Background(), sqsEventPayload(t, "ok", "flaky", "poison"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := failureIDs(res.(events.SQSEventResponse)); len(got) != 0 {
+		t.Errorf("failures = %v, want none after forwarding", got)
+	}
+	if want := map[string]int{"ok": 1, "flaky": 2, "poison": 3}; !reflect.DeepEqual(attempts, want) {
+		t.Errorf("attempts = %v, want %v", attempts, want)
+	}
+	if len(client.sent) != 1 || aws.ToString(client.sent[0].MessageBody) != "poison" {
+		t.Fatalf("forwarded %d messages, want only poison", len(client.sent))
+	}
+	if got := aws.ToString(client.sent[0].MessageAttributes[AttrOriginalError].StringValue); got != "cannot parse" {
+		t.Errorf("%s = %q, want the handler's error", AttrOriginalError, got)
+	}
+}
+
+func TestDispatcherPolicyKinesis(t *testing.T) {
+	calls := 0
+	d := NewDispatcher()
+	d.Handle(SourceKinesis, KinesisHandler(func(ctx context.Context, rec KinesisRecord) error {
+		calls++
+		if rec.SequenceNumber == "1" {
+			return errors.New("failed")
+		}
+		return nil
+	}))
+	if err := d.WithPolicy(SourceKinesis, Policy{MaxRetries: 1, Backoff: time.Millisecond}); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := d.Dispatch(context.Background(), kinesisEventPayload("b25l", "dHdv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []events.KinesisBatchItemFailure{{ItemIdentifier: "1"}}
+	if got := res.(events.KinesisEventResponse).BatchItemFailures; !reflect.DeepEqual(got, want) {
+		t.Errorf("failures = %v, want %v", got, want)
+	}
+	if calls != 3 {
+		t.Errorf("calls = %d, want 3 (two records, then one retry)", calls)
+	}
+}
+
+func TestDispatcherPolicyRetriesWholeEvent(t *testing.T) {
+	calls := 0
+	d := NewDispatcher()
+	d.Handle(SourceEventBridge, func(ctx context.Context, event json.RawMessage) (any, error) {
+		calls++
+		if calls < 3 {
+			return nil, errors.New("unavailable")
+		}
+		return "done", nil
+	})
+	if err := d.WithPolicy(SourceEventBridge, Policy{MaxRetries: 2, Backoff: time.Millisecond}); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := d.Dispatch(context.Background(), json.RawMessage(`{"detail-type":"Tick","detail":{}}`))
+	if err != nil || res != "done" || calls != 3 {
+		t.Errorf("Dispatch() = %v, %v after %d calls", res, err, calls)
+	}
+}
+
+func TestWithPolicyValidation(t *testing.T) {
+	d := NewDispatcher()
+	tests := []struct {
+		name   string
+		source EventSource
+		policy Policy
+	}{
+		{"dlq on kinesis", SourceKinesis, Policy{DeadLetterQueue: "https://sqs/dlq", SQSClient: &fakeSQS{}}},
+		{"dlq without client", SourceSQS, Policy{DeadLetterQueue: "https://sqs/dlq"}},
+		{"negative retries", SourceS3, Policy{MaxRetries: -1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := d.WithPolicy(tt.source, tt.policy); !errors.Is(err, ErrInvalidPolicy) {
+				t.Errorf("WithPolicy() = %v, want ErrInvalidPolicy", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package rambda
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Provider lazily creates a resource once per container and reuses it across warm invocations,
+// which keeps expensive setup such as opening connections out of the per-invocation path.
+//
+// A Provider is safe for concurrent use. Concurrent callers of Get during initialization
+// wait for the single init call and all receive its result. An init error is cached as
+// well, so a broken dependency is not retried on every invocation; use WithTTL to allow
+// a later retry and to recycle stale values. A panic in init is cached as a *PanicError.
+type Provider[T any] struct {
+	init func(ctx context.Context) (T, error)
+	ttl  time.Duration
+
+	mu       sync.Mutex
+	once     *sync.Once
+	value    T
+	err      error
+	loadedAt time.Time
+}
+
+// NewProvider returns a Provider that creates its value with init.
+func NewProvider[T any](init func(ctx context.Context) (T, error), opts ...Option) *Provider[T] {
+	o := newOptions(opts)
+	return &Provider[T]{
+		init: init,
+		ttl:  o.ttl,
+		once: new(sync.Once),
+	}
+}
+
+// Get returns the cached value, initializing it on first use or after the TTL has expired.
+func (p *Provider[T]) Get(ctx context.Context) (T, error) {
+	p.mu.Lock()
+	if p.ttl > 0 && !p.loadedAt.IsZero() && time.Since(p.loadedAt) >= p.ttl {
+		// 期限切れなので次の呼び出しで再初期化させる
+		p.once = new(sync.Once)
+		p.loadedAt = time.Time{}
+	}
+	once := p.once
+	p.mu.Unlock()
+
+	once.Do(func() {
+		var value T
+		// init が panic しても Once は完了扱いになるので、エラーとして記録しておく
+		err := safeCall(func() error {
+			var err error
+			value, err = p.init(ctx)
+			return err
+		})
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.value, p.err, p.loadedAt = value, err, time.Now()
+	})
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.value, p.err
+}
@@ -0,0 +1,89 @@
+package rambda
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestProviderInitializesOnce(t *testing.T) {
+	var calls atomic.Int32
+	p := NewProvider(func(ctx context.Context) (int, error) {
+		calls.Add(1)
+		time.Sleep(10 * time.Millisecond)
+		return 42, nil
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v, err := p.Get(context.Background()); v != 42 || err != nil {
+				t.Errorf("Get() = (%d, %v)", v, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if calls.Load() != 1 {
+		t.Errorf("init called %d times, want 1", calls.Load())
+	}
+}
+
+func TestProviderCachesError(t *testing.T) {
+	calls := 0
+	wantErr := errors.New("connect failed")
+	p := NewProvider(func(ctx context.Context) (string, error) {
+		calls++
+		return "", wantErr
+	})
+
+	for i := 0; i < 3; i++ {
+		if _, err := p.Get(context.Background()); err != wantErr {
+			t.Fatalf("Get() error = %v", err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("init called %d times, want 1", calls)
+	}
+}
+
+func TestProviderCachesPanic(t *testing.T) {
+	p := NewProvider(func(ctx context.Context) (*strings.Builder, error) {
+		panic("driver not registered")
+	})
+
+	for i := 0; i < 2; i++ {
+		v, err := p.Get(context.Background())
+		var pe *PanicError
+		if !errors.As(err, &pe) || pe.Value != "driver not registered" {
+			t.Fatalf("Get() #%d error = %v, want a *PanicError", i+1, err)
+		}
+		if v != nil {
+			t.Errorf("Get() #%d value = %v, want nil", i+1, v)
+		}
+	}
+}
+
+func TestProviderTTL(t *testing.T) {
+	calls := 0
+	p := NewProvider(func(ctx context.Context) (int, error) {
+		calls++
+		return calls, nil
+	}, WithTTL(20*time.Millisecond))
+
+	if v, _ := p.Get(context.Background()); v != 1 {
+		t.Fatalf("Get() = %d, want 1", v)
+	}
+	if v, _ := p.Get(context.Background()); v != 1 {
+		t.Fatalf("Get() = %d, want cached 1", v)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if v, _ := p.Get(context.Background()); v != 2 {
+		t.Errorf("Get() = %d, want 2 after TTL", v)
+	}
+}
@@ -0,0 +1,48 @@
+package rambda
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError is returned by RecoverMiddleware when the wrapped handler panics.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("rambda: handler panicked: %v", e.Value)
+}
+
+// Unwrap returns the panic value when it is itself an error, such as a runtime.Error.
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+	return nil
+}
+
+// RecoverMiddleware converts a panic in the wrapped handler into a *PanicError,
+// keeping the process alive for the next invocation in a warm container.
+// A panic whose value is already a *PanicError is returned as it is.
+func RecoverMiddleware() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, event json.RawMessage) (res any, err error) {
+			defer func() {
+				if v := recover(); v != nil {
+					res = nil
+					// TimeoutMiddleware などが投げ直した PanicError は元のスタックを保つ
+					if pe, ok := v.(*PanicError); ok {
+						err = pe
+						return
+					}
+					err = &PanicError{Value: v, Stack: debug.Stack()}
+				}
+			}()
+			return next(ctx, event)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package rambda
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"runtime"
+	"testing"
+)
+
+func TestRecoverMiddleware(t *testing.T) {
+	type custom struct{ Code int }
+
+	tests := []struct {
+		name  string
+		panic func()
+		check func(t *testing.T, pe *PanicError)
+	}{
+		{
+			name: "runtime error",
+			panic: func() {
+				var m map[string]int
+				m["x"] = 1
+			},
+			check: func(t *testing.T, pe *PanicError) {
+				var re runtime.Error
+				if !errors.As(pe, &re) {
+					t.Errorf("expected runtime.Error to be unwrappable, got %T", pe.Value)
+				}
+			},
+		},
+		{
+			name:  "string",
+			panic: func() { panic("boom") },
+			check: func(t *testing.T, pe *PanicError) {
+				if pe.Value != "boom" {
+					t.Errorf("Value = %v, want boom", pe.Value)
+				}
+			},
+		},
+		{
+			name:  "struct",
+			panic: func() { panic(custom{Code: 7}) },
+			check: func(t *testing.T, pe *PanicError) {
+				if pe.Value != (custom{Code: 7}) {
+					t.Errorf("Value = %v", pe.Value)
+				}
+				if pe.Unwrap() != nil {
+					t.Errorf("Unwrap() = %v, want nil", pe.Unwrap())
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := Chain(func(ctx context.Context, event json.RawMessage) (any, error) {
+				tt.panic()
+				return "unreachable", nil
+			}, RecoverMiddleware())
+
+			res, err := h(context.Background(), nil)
+			if res != nil {
+				t.Errorf("res = %v, want nil", res)
+			}
+			var pe *PanicError
+			if !errors.As(err, &pe) {
+				t.Fatalf("error = %v, want *PanicError", err)
+			}
+			if len(pe.Stack) == 0 {
+				t.Error("Stack is empty")
+			}
+			tt.check(t, pe)
+		})
+	}
+}
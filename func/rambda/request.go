@@ -0,0 +1,96 @@
+package rambda
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// APIRequest is an API Gateway HTTP API (payload format 2.0) request.
+// A base64 encoded body is decoded by APIHandler before the handler sees it.
+type APIRequest struct {
+	events.APIGatewayV2HTTPRequest
+
+	rawBody []byte
+}
+
+// RawBody returns the request body as raw bytes, already base64 decoded when needed.
+func (r *APIRequest) RawBody() []byte {
+	if r.rawBody == nil {
+		return []byte(r.Body)
+	}
+	return r.rawBody
+}
+
+// decodeBody replaces a base64 encoded body with its decoded form.
+func (r *APIRequest) decodeBody() error {
+	if !r.IsBase64Encoded {
+		return nil
+	}
+	b, err := decodeBase64Body(r.Body)
+	if err != nil {
+		return err
+	}
+	r.rawBody = b
+	r.Body = string(b)
+	r.IsBase64Encoded = false
+	return nil
+}
+
+func decodeBase64Body(body string) ([]byte, error) {
+	b, err := base64.StdEncoding.DecodeString(body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid base64 body: %v", ErrBadRequest, err)
+	}
+	return b, nil
+}
+
+// decodeEventBody decodes the base64 body of API Gateway event types in place.
+// Other types are left untouched.
+func decodeEventBody(v any) error {
+	switch req := v.(type) {
+	case *APIRequest:
+		return req.decodeBody()
+	case *events.APIGatewayV2HTTPRequest:
+		if req.IsBase64Encoded {
+			b, err := decodeBase64Body(req.Body)
+			if err != nil {
+				return err
+			}
+			req.Body, req.IsBase64Encoded = string(b), false
+		}
+	case *events.APIGatewayProxyRequest:
+		if req.IsBase64Encoded {
+			b, err := decodeBase64Body(req.Body)
+			if err != nil {
+				return err
+			}
+			req.Body, req.IsBase64Encoded = string(b), false
+		}
+	}
+	return nil
+}
+
+// APIHandler adapts fn into a Handler for API Gateway HTTP API events.
+// Errors returned by fn are turned into responses by the ErrorMapper set with WithErrorMapper.
+// A body that claims to be base64 encoded but is not results in a 400 response.
+func APIHandler(fn func(ctx context.Context, req *APIRequest) (APIResponse, error), opts ...Option) Handler {
+	o := newOptions(opts)
+	return func(ctx context.Context, event json.RawMessage) (any, error) {
+		var req APIRequest
+		if err := unmarshal(event, &req); err != nil {
+			return nil, newDecodeError(&req, event, err)
+		}
+		if err := req.decodeBody(); err != nil {
+			return o.errorMapper.MapError(err), nil
+		}
+		res, err := fn(ctx, &req)
+		if err != nil {
+			return o.errorMapper.MapError(err), nil
+		}
+		return res, nil
+	}
+}
@@ -0,0 +1,63 @@
+package rambda
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestAPIHandlerDecodesBase64Body(t *testing.T) {
+	var raw []byte
+	h := APIHandler(func(ctx context.Context, req *APIRequest) (APIResponse, error) {
+		raw = req.RawBody()
+		return Text(http.StatusOK, req.Body), nil
+	})
+
+	// "\x89PNG" を base64 にしたもの
+	res, err := h(context.Background(), json.RawMessage(`{"body":"iVBORw==","isBase64Encoded":true}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(raw) != "\x89PNG" || res.(APIResponse).Body != "\x89PNG" {
+		t.Errorf("raw = %q, body = %q", raw, res.(APIResponse).Body)
+	}
+
+	res, err = h(context.Background(), json.RawMessage(`{"body":"not base64!","isBase64Encoded":true}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.(APIResponse).StatusCode != http.StatusBadRequest {
+		t.Errorf("StatusCode = %d, want 400", res.(APIResponse).StatusCode)
+	}
+}
+
+func TestAPIRequestRawBodyPlain(t *testing.T) {
+	req := &APIRequest{}
+	req.Body = `{"a":1}`
+	if string(req.RawBody()) != `{"a":1}` {
+		t.Errorf("RawBody() = %q", req.RawBody())
+	}
+}
+
+func TestTypedDecodesBase64Body(t *testing.T) {
+	h := Typed(func(ctx context.Context, req events.APIGatewayV2HTTPRequest) (string, error) {
+		return req.Body, nil
+	})
+
+	res, err := h(context.Background(), json.RawMessage(`{"body":"aGVsbG8=","isBase64Encoded":true}`))
+	if err != nil || res != "hello" {
+		t.Errorf("got (%v, %v), want hello", res, err)
+	}
+
+	b, err := Invoke(h, []byte(`{"body":"%%%","isBase64Encoded":true}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var apiRes APIResponse
+	if err := json.Unmarshal(b, &apiRes); err != nil || apiRes.StatusCode != http.StatusBadRequest {
+		t.Errorf("Invoke() = %s, want statusCode 400", b)
+	}
+}
@@ -0,0 +1,83 @@
+package rambda
+
+import (
+	"net/http"
+	"strings"
+)
+
+// APIResponse is an API Gateway proxy response.
+// Returning it from a handler lets lambda.Start serialize it in the shape API Gateway expects.
+type APIResponse struct {
+	StatusCode int               `json:"statusCode"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	Body       string            `json:"body"`
+	// IsBase64Encoded tells API Gateway to decode Body before sending it, as needed for binary bodies.
+	IsBase64Encoded bool `json:"isBase64Encoded,omitempty"`
+}
+
+// JSON returns a response whose body is v marshaled as JSON.
+// If v cannot be marshaled, a 500 response is returned instead.
+func JSON(status int, v any) APIResponse {
+	body, err := marshal(v)
+	if err != nil {
+		return Text(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+	}
+	return APIResponse{
+		StatusCode: status,
+		Headers:    map[string]string{"Content-Type": "application/json"},
+		Body:       string(body),
+	}
+}
+
+// Text returns a plain text response.
+func Text(status int, body string) APIResponse {
+	return APIResponse{
+		StatusCode: status,
+		Headers:    map[string]string{"Content-Type": "text/plain; charset=utf-8"},
+		Body:       body,
+	}
+}
+
+// NoContent returns an empty 204 response.
+func NoContent() APIResponse {
+	return APIResponse{
+		StatusCode: http.StatusNoContent,
+	}
+}
+
+// asAPIResponse reports whether a handler result is an APIResponse.
+func asAPIResponse(v any) (APIResponse, bool) {
+	switch res := v.(type) {
+	case APIResponse:
+		return res, true
+	case *APIResponse:
+		if res != nil {
+			return *res, true
+		}
+	}
+	return APIResponse{}, false
+}
+
+// withHeader returns a copy of res with the header set, leaving the original map untouched.
+func (r APIResponse) withHeader(key, value string) APIResponse {
+	headers := make(map[string]string, len(r.Headers)+1)
+	for k, v := range r.Headers {
+		headers[k] = v
+	}
+	headers[key] = value
+	r.Headers = headers
+	return r
+}
+
+// header looks up a header case-insensitively.
+func header(headers map[string]string, key string) (string, bool) {
+	if v, ok := headers[key]; ok {
+		return v, true
+	}
+	for k, v := range headers {
+		if strings.EqualFold(k, key) {
+			return v, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,44 @@
+package rambda
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestJSON(t *testing.T) {
+	res := JSON(http.StatusCreated, map[string]string{"id": "1"})
+	if res.StatusCode != http.StatusCreated {
+		t.Errorf("StatusCode = %d, want %d", res.StatusCode, http.StatusCreated)
+	}
+	if got := res.Headers["Content-Type"]; got != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", got)
+	}
+	if res.Body != `{"id":"1"}` {
+		t.Errorf("Body = %q", res.Body)
+	}
+
+	res = JSON(http.StatusOK, make(chan int))
+	if res.StatusCode != http.StatusInternalServerError {
+		t.Errorf("StatusCode = %d, want %d for unmarshalable value", res.StatusCode, http.StatusInternalServerError)
+	}
+}
+
+func TestAPIResponseSerialization(t *testing.T) {
+	b, err := json.Marshal(Text(http.StatusOK, "hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"statusCode":200,"headers":{"Content-Type":"text/plain; charset=utf-8"},"body":"hello"}`
+	if string(b) != want {
+		t.Errorf("got %s, want %s", b, want)
+	}
+
+	b, err = json.Marshal(NoContent())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"statusCode":204,"body":""}`; string(b) != want {
+		t.Errorf("got %s, want %s", b, want)
+	}
+}
@@ -0,0 +1,115 @@
+package rambda
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
+
+const (
+	defaultMaxAttempts = 3
+	defaultBaseDelay   = 100 * time.Millisecond
+	defaultMaxDelay    = 5 * time.Second
+)
+
+// RetryableError marks an error as transient so that Retry tries again.
+type RetryableError struct {
+	Err error
+}
+
+func (e *RetryableError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RetryableError) Unwrap() error {
+	return e.Err
+}
+
+// Retryable marks err as transient. It returns nil for a nil err.
+func Retryable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &RetryableError{Err: err}
+}
+
+// IsRetryable reports whether err is marked with Retryable.
+func IsRetryable(err error) bool {
+	var re *RetryableError
+	return errors.As(err, &re)
+}
+
+// RetryError is returned by Retry when it gives up.
+type RetryError struct {
+	Attempts int
+	Err      error
+}
+
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("rambda: giving up after %d attempts: %v", e.Attempts, e.Err)
+}
+
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
+
+// Retry calls fn until it succeeds, returns an error that is not retryable, or the
+// attempts set with WithMaxAttempts (3 by default) are used up.
+//
+// Retries wait with exponential backoff and full jitter, configured with WithBackoff.
+// Which errors are retried is decided by WithRetryIf, defaulting to IsRetryable.
+// Retry stops early when ctx is done or when the time left before its deadline is
+// shorter than the next backoff plus the duration of the slowest attempt so far.
+// When it gives up after retrying, the last error is wrapped in a *RetryError.
+func Retry(ctx context.Context, fn func() error, opts ...Option) error {
+	o := newOptions(opts)
+	retryIf := o.retryIf
+	if retryIf == nil {
+		retryIf = IsRetryable
+	}
+
+	var slowest time.Duration
+	for attempt := 1; ; attempt++ {
+		start := time.Now()
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		slowest = max(slowest, time.Since(start))
+
+		if !retryIf(err) {
+			return err
+		}
+		if attempt >= o.maxAttempts {
+			return &RetryError{Attempts: attempt, Err: err}
+		}
+
+		delay := backoff(o.baseDelay, o.maxDelay, attempt)
+		// 次の試行が期限内に終わらないならここで諦める
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay+slowest {
+			return &RetryError{Attempts: attempt, Err: err}
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return &RetryError{Attempts: attempt, Err: err}
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff returns a random delay in [0, min(max, base*2^(attempt-1))].
+func backoff(base, maxDelay time.Duration, attempt int) time.Duration {
+	d := base << (attempt - 1)
+	if d <= 0 || d > maxDelay {
+		d = maxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	return rand.N(d + 1)
+}
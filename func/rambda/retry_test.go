@@ -0,0 +1,84 @@
+package rambda
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRetry(t *testing.T) {
+	calls := 0
+	err := Retry(context.Background(), func() error {
+		calls++
+		if calls < 3 {
+			return Retryable(errors.New("throttled"))
+		}
+		return nil
+	}, WithBackoff(time.Millisecond, 5*time.Millisecond), WithMaxAttempts(5))
+	if err != nil || calls != 3 {
+		t.Errorf("Retry() = %v after %d calls, want success after 3", err, calls)
+	}
+}
+
+func TestRetryExhausted(t *testing.T) {
+	transient := errors.New("throttled")
+	calls := 0
+	err := Retry(context.Background(), func() error {
+		calls++
+		return Retryable(transient)
+	}, WithBackoff(time.Millisecond, time.Millisecond))
+
+	var re *RetryError
+	if !errors.As(err, &re) || re.Attempts != defaultMaxAttempts || calls != defaultMaxAttempts {
+		t.Fatalf("Retry() = %v after %d calls", err, calls)
+	}
+	if !errors.Is(err, transient) {
+		t.Error("RetryError should wrap the last error")
+	}
+}
+
+func TestRetryPermanentError(t *testing.T) {
+	permanent := errors.New("bad request")
+	calls := 0
+	err := Retry(context.Background(), func() error {
+		calls++
+		return permanent
+	})
+	if err != permanent || calls != 1 {
+		t.Errorf("Retry() = %v after %d calls, want the error after 1", err, calls)
+	}
+
+	calls = 0
+	_ = Retry(context.Background(), func() error {
+		calls++
+		return permanent
+	}, WithRetryIf(func(error) bool { return true }), WithBackoff(0, 0))
+	if calls != defaultMaxAttempts {
+		t.Errorf("custom predicate: %d calls, want %d", calls, defaultMaxAttempts)
+	}
+}
+
+func TestRetryStopsBeforeDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	calls := 0
+	start := time.Now()
+	err := Retry(ctx, func() error {
+		calls++
+		time.Sleep(30 * time.Millisecond)
+		return Retryable(errors.New("slow"))
+	}, WithMaxAttempts(10), WithBackoff(time.Millisecond, time.Millisecond))
+
+	var re *RetryError
+	if !errors.As(err, &re) {
+		t.Fatalf("error = %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1 since a second attempt cannot finish in time", calls)
+	}
+	if time.Since(start) > 50*time.Millisecond {
+		t.Error("Retry ran past the deadline")
+	}
+}
@@ -0,0 +1,147 @@
+package rambda
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// RouteHandler handles a request matched by a Router.
+type RouteHandler func(ctx context.Context, req *APIRequest) (APIResponse, error)
+
+// Router dispatches API Gateway HTTP API requests by method and path.
+//
+// Patterns are matched segment by segment against rawPath; a segment written as {name}
+// matches any value and is exposed in req.PathParameters. Unknown paths get a 404 and
+// known paths with another method get a 405 with an Allow header.
+//
+// Routes are tried in registration order and the first match wins, so register literal
+// paths such as /users/me before patterns like /users/{id} that would also match them.
+//
+// A *Router can be passed directly to lambda.Start.
+type Router struct {
+	routes     []route
+	opts       []Option
+	middleware []Middleware
+
+	once    sync.Once
+	handler Handler
+}
+
+type route struct {
+	method   string
+	segments []string
+	handler  RouteHandler
+}
+
+// NewRouter returns an empty Router. Options apply to the Handler it produces, such as WithErrorMapper.
+func NewRouter(opts ...Option) *Router {
+	return &Router{opts: opts}
+}
+
+// Handle registers h for requests with method whose path matches pattern.
+func (r *Router) Handle(method, pattern string, h RouteHandler) {
+	r.routes = append(r.routes, route{
+		method:   strings.ToUpper(method),
+		segments: splitPath(pattern),
+		handler:  h,
+	})
+}
+
+func (r *Router) GET(pattern string, h RouteHandler)    { r.Handle(http.MethodGet, pattern, h) }
+func (r *Router) POST(pattern string, h RouteHandler)   { r.Handle(http.MethodPost, pattern, h) }
+func (r *Router) PUT(pattern string, h RouteHandler)    { r.Handle(http.MethodPut, pattern, h) }
+func (r *Router) PATCH(pattern string, h RouteHandler)  { r.Handle(http.MethodPatch, pattern, h) }
+func (r *Router) DELETE(pattern string, h RouteHandler) { r.Handle(http.MethodDelete, pattern, h) }
+
+// Use adds middleware around every request handled by the router, including unmatched ones.
+// The first middleware added is the outermost.
+//
+// The middleware is fixed once Handler or Invoke is first called; Use panics after that.
+func (r *Router) Use(mw ...Middleware) {
+	if r.handler != nil {
+		panic("rambda: Router.Use called after the router was built")
+	}
+	r.middleware = append(r.middleware, mw...)
+}
+
+// Handler returns the router and its middleware as a Handler.
+// The chain is built on the first call and reused afterwards.
+func (r *Router) Handler() Handler {
+	r.once.Do(func() {
+		r.handler = Chain(APIHandler(r.serve, r.opts...), r.middleware...)
+	})
+	return r.handler
+}
+
+// Invoke implements lambda.Handler.
+func (r *Router) Invoke(ctx context.Context, payload []byte) ([]byte, error) {
+	return r.Handler().Invoke(ctx, payload)
+}
+
+func (r *Router) serve(ctx context.Context, req *APIRequest) (APIResponse, error) {
+	path := splitPath(req.RawPath)
+	method := strings.ToUpper(req.RequestContext.HTTP.Method)
+
+	var allowed []string
+	for _, rt := range r.routes {
+		params, ok := matchPath(rt.segments, path)
+		if !ok {
+			continue
+		}
+		if rt.method != method {
+			if !slices.Contains(allowed, rt.method) {
+				allowed = append(allowed, rt.method)
+			}
+			continue
+		}
+		if len(params) > 0 {
+			if req.PathParameters == nil {
+				req.PathParameters = make(map[string]string, len(params))
+			}
+			for k, v := range params {
+				req.PathParameters[k] = v
+			}
+		}
+		return rt.handler(ctx, req)
+	}
+
+	if len(allowed) > 0 {
+		sort.Strings(allowed)
+		res := JSON(http.StatusMethodNotAllowed, map[string]string{"error": http.StatusText(http.StatusMethodNotAllowed)})
+		return res.withHeader("Allow", strings.Join(allowed, ", ")), nil
+	}
+	return JSON(http.StatusNotFound, map[string]string{"error": http.StatusText(http.StatusNotFound)}), nil
+}
+
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+// matchPath matches path against pattern and returns the values of its {name} segments.
+func matchPath(pattern, path []string) (map[string]string, bool) {
+	if len(pattern) != len(path) {
+		return nil, false
+	}
+	var params map[string]string
+	for i, seg := range pattern {
+		if name, ok := strings.CutPrefix(seg, "{"); ok && strings.HasSuffix(name, "}") {
+			if params == nil {
+				params = make(map[string]string)
+			}
+			params[strings.TrimSuffix(name, "}")] = path[i]
+			continue
+		}
+		if seg != path[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
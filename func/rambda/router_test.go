@@ -0,0 +1,132 @@
+package rambda
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+var _ lambda.Handler = (*Router)(nil)
+
+func apiEvent(method, path string) json.RawMessage {
+	return json.RawMessage(fmt.Sprintf(`{"rawPath":%q,"requestContext":{"http":{"method":%q}}}`, path, method))
+}
+
+func newTestRouter() *Router {
+	r := NewRouter()
+	r.GET("/users", func(ctx context.Context, req *APIRequest) (APIResponse, error) {
+		return Text(http.StatusOK, "list"), nil
+	})
+	r.GET("/users/{id}", func(ctx context.Context, req *APIRequest) (APIResponse, error) {
+		return Text(http.StatusOK, "user "+req.PathParameters["id"]), nil
+	})
+	r.DELETE("/users/{id}", func(ctx context.Context, req *APIRequest) (APIResponse, error) {
+		return APIResponse{}, ErrNotFound
+	})
+	r.POST("/users", func(ctx context.Context, req *APIRequest) (APIResponse, error) {
+		return NoContent(), nil
+	})
+	return r
+}
+
+func TestRouter(t *testing.T) {
+	h := newTestRouter().Handler()
+
+	tests := []struct {
+		method, path string
+		wantStatus   int
+		wantBody     string
+		wantAllow    string
+	}{
+		{"GET", "/users", http.StatusOK, "list", ""},
+		{"GET", "/users/42/", http.StatusOK, "user 42", ""},
+		{"POST", "/users", http.StatusNoContent, "", ""},
+		{"DELETE", "/users/42", http.StatusNotFound, `{"error":"rambda: not found"}`, ""},
+		{"PUT", "/users/42", http.StatusMethodNotAllowed, `{"error":"Method Not Allowed"}`, "DELETE, GET"},
+		{"GET", "/orders", http.StatusNotFound, `{"error":"Not Found"}`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			res, err := h(context.Background(), apiEvent(tt.method, tt.path))
+			if err != nil {
+				t.Fatal(err)
+			}
+			apiRes := res.(APIResponse)
+			if apiRes.StatusCode != tt.wantStatus || apiRes.Body != tt.wantBody {
+				t.Errorf("got %d %q, want %d %q", apiRes.StatusCode, apiRes.Body, tt.wantStatus, tt.wantBody)
+			}
+			if apiRes.Headers["Allow"] != tt.wantAllow {
+				t.Errorf("Allow = %q, want %q", apiRes.Headers["Allow"], tt.wantAllow)
+			}
+		})
+	}
+}
+
+func TestRouterInvoke(t *testing.T) {
+	b, err := newTestRouter().Invoke(context.Background(), apiEvent("GET", "/users/7"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"statusCode":200,"headers":{"Content-Type":"text/plain; charset=utf-8"},"body":"user 7"}`
+	if string(b) != want {
+		t.Errorf("Invoke() = %s, want %s", b, want)
+	}
+}
+
+func TestRouterBuildsChainOnce(t *testing.T) {
+	r := newTestRouter()
+	built := 0
+	r.Use(func(next Handler) Handler {
+		built++
+		return next
+	})
+
+	for i := 0; i < 3; i++ {
+		if _, err := r.Invoke(context.Background(), apiEvent("GET", "/users/7")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if built != 1 {
+		t.Errorf("middleware built %d times, want 1", built)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected Use to panic after the router was built")
+		}
+	}()
+	r.Use(func(next Handler) Handler { return next })
+}
+
+func TestRouterOverlappingRoutes(t *testing.T) {
+	r := NewRouter()
+	r.GET("/users/me", func(ctx context.Context, req *APIRequest) (APIResponse, error) {
+		return Text(http.StatusOK, "me"), nil
+	})
+	r.GET("/users/{id}", func(ctx context.Context, req *APIRequest) (APIResponse, error) {
+		return Text(http.StatusOK, "user "+req.PathParameters["id"]), nil
+	})
+	h := r.Handler()
+
+	for path, want := range map[string]string{"/users/me": "me", "/users/7": "user 7"} {
+		res, err := h(context.Background(), apiEvent("GET", path))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := res.(APIResponse).Body; got != want {
+			t.Errorf("GET %s = %q, want %q", path, got, want)
+		}
+	}
+
+	res, err := h(context.Background(), apiEvent("POST", "/users/me"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := res.(APIResponse).Headers["Allow"]; got != "GET" {
+		t.Errorf("Allow = %q, want GET", got)
+	}
+}
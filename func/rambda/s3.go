@@ -0,0 +1,112 @@
+package rambda
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// S3Client is the subset of the S3 API used by S3Handler.
+type S3Client interface {
+	HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error)
+}
+
+// S3Object is an object referenced by an S3 event notification.
+type S3Object struct {
+	Bucket string
+	// Key is the object key with the URL encoding of the event removed.
+	Key string
+
+	client func(ctx context.Context) (S3Client, error)
+
+	once sync.Once
+	head *s3.HeadObjectOutput
+	err  error
+}
+
+// Head fetches the object's metadata with HeadObject on first use and caches the result.
+func (o *S3Object) Head(ctx context.Context) (*s3.HeadObjectOutput, error) {
+	o.once.Do(func() {
+		client, err := o.client(ctx)
+		if err != nil {
+			o.err = err
+			return
+		}
+		o.head, o.err = client.HeadObject(ctx, &s3.HeadObjectInput{
+			Bucket: aws.String(o.Bucket),
+			Key:    aws.String(o.Key),
+		})
+	})
+	return o.head, o.err
+}
+
+// S3Handler adapts a per-object function into a Handler for S3 event notifications.
+//
+// Every record is processed even when some fail; the returned error joins one error per
+// failed object, each naming its s3://bucket/key. Records are processed in order unless
+// WithConcurrency allows several at once. Once ctx is done no further records are started
+// and those left are reported with the context's error.
+func S3Handler(fn func(ctx context.Context, obj *S3Object) error, opts ...Option) Handler {
+	o := newOptions(opts)
+	client := lazyS3Client(o.s3Client)
+
+	return func(ctx context.Context, event json.RawMessage) (any, error) {
+		var s3Event events.S3Event
+		if err := unmarshal(event, &s3Event); err != nil {
+			return nil, newDecodeError(&s3Event, event, err)
+		}
+
+		errs := make([]error, len(s3Event.Records))
+		processBatch(len(s3Event.Records), o.concurrency, func(i int) error {
+			record := s3Event.Records[i]
+			bucket := record.S3.Bucket.Name
+			// イベントのキーはURLエンコードされている (スペースは "+")
+			key, err := url.QueryUnescape(record.S3.Object.Key)
+			if err != nil {
+				errs[i] = fmt.Errorf("rambda: decode s3 key %q: %w", record.S3.Object.Key, err)
+				return errs[i]
+			}
+			if err := ctx.Err(); err != nil {
+				errs[i] = fmt.Errorf("rambda: s3 object s3://%s/%s not processed: %w", bucket, key, err)
+				return errs[i]
+			}
+			obj := &S3Object{Bucket: bucket, Key: key, client: client}
+			if err := safeCall(func() error { return fn(ctx, obj) }); err != nil {
+				errs[i] = fmt.Errorf("rambda: s3 object s3://%s/%s: %w", bucket, key, err)
+			}
+			return errs[i]
+		})
+		return nil, errors.Join(errs...)
+	}
+}
+
+// lazyS3Client returns client as is, or builds one from the default AWS config the first time it is needed.
+func lazyS3Client(client S3Client) func(ctx context.Context) (S3Client, error) {
+	if client != nil {
+		return func(context.Context) (S3Client, error) { return client, nil }
+	}
+	var (
+		once sync.Once
+		err  error
+	)
+	return func(ctx context.Context) (S3Client, error) {
+		once.Do(func() {
+			var cfg aws.Config
+			cfg, err = config.LoadDefaultConfig(ctx)
+			if err != nil {
+				err = fmt.Errorf("rambda: load aws config: %w", err)
+				return
+			}
+			client = s3.NewFromConfig(cfg)
+		})
+		return client, err
+	}
+}
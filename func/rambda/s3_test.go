@@ -0,0 +1,116 @@
+package rambda
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+type fakeS3Client struct {
+	calls int
+}
+
+func (c *fakeS3Client) HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+	c.calls++
+	return &s3.HeadObjectOutput{
+		ContentLength: aws.Int64(42),
+		ContentType:   aws.String("image/png"),
+	}, nil
+}
+
+func TestS3Handler(t *testing.T) {
+	payload := `{"Records":[{"eventSource":"aws:s3","s3":{"bucket":{"name":"photos"},"object":{"key":"my+cat%281%29.png"}}}]}`
+	client := &fakeS3Client{}
+
+	var seen []string
+	h := S3Handler(func(ctx context.Context, obj *S3Object) error {
+		seen = append(seen, obj.Bucket+"/"+obj.Key)
+		for i := 0; i < 2; i++ {
+			head, err := obj.Head(ctx)
+			if err != nil {
+				return err
+			}
+			if aws.ToInt64(head.ContentLength) != 42 || aws.ToString(head.ContentType) != "image/png" {
+				t.Errorf("head = %+v", head)
+			}
+		}
+		return nil
+	}, WithS3Client(client))
+
+	if _, err := h(context.Background(), json.RawMessage(payload)); err != nil {
+		t.Fatal(err)
+	}
+	if len(seen) != 1 || seen[0] != "photos/my cat(1).png" {
+		t.Errorf("seen = %v", seen)
+	}
+	if client.calls != 1 {
+		t.Errorf("HeadObject called %d times, want 1", client.calls)
+	}
+}
+
+func s3EventPayload(keys ...string) json.RawMessage {
+	records := make([]string, len(keys))
+	for i, key := range keys {
+		records[i] = fmt.Sprintf(`{"eventSource":"aws:s3","s3":{"bucket":{"name":"photos"},"object":{"key":%q}}}`, key)
+	}
+	return json.RawMessage(`{"Records":[` + strings.Join(records, ",") + `]}`)
+}
+
+func TestS3HandlerConcurrentErrors(t *testing.T) {
+	var running, peak atomic.Int32
+	var processed atomic.Int32
+	h := S3Handler(func(ctx context.Context, obj *S3Object) error {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		processed.Add(1)
+		if strings.HasPrefix(obj.Key, "bad") {
+			return errors.New("corrupt")
+		}
+		return nil
+	}, WithS3Client(&fakeS3Client{}), WithConcurrency(3))
+
+	_, err := h(context.Background(), s3EventPayload("a.png", "bad-1.png", "b.png", "c.png", "bad-2.png", "d.png"))
+	if processed.Load() != 6 {
+		t.Errorf("processed %d objects, want all 6", processed.Load())
+	}
+	if peak.Load() > 3 {
+		t.Errorf("peak concurrency = %d, want <= 3", peak.Load())
+	}
+	want := "rambda: s3 object s3://photos/bad-1.png: corrupt\nrambda: s3 object s3://photos/bad-2.png: corrupt"
+	if err == nil || err.Error() != want {
+		t.Errorf("error = %v, want %q", err, want)
+	}
+}
+
+func TestS3HandlerContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	h := S3Handler(func(ctx context.Context, obj *S3Object) error {
+		calls++
+		cancel()
+		return nil
+	}, WithS3Client(&fakeS3Client{}))
+
+	_, err := h(ctx, s3EventPayload("a.png", "b.png", "c.png"))
+	if calls != 1 {
+		t.Errorf("fn called %d times, want 1 before cancellation", calls)
+	}
+	if !errors.Is(err, context.Canceled) || !strings.Contains(err.Error(), "s3://photos/c.png not processed") {
+		t.Errorf("error = %v", err)
+	}
+}
@@ -0,0 +1,39 @@
+package rambda
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrNotScheduledEvent is returned by ScheduledHandler for payloads that are not scheduled events.
+var ErrNotScheduledEvent = errors.New("rambda: not a scheduled event")
+
+const scheduledDetailType = "Scheduled Event"
+
+type scheduledEvent struct {
+	DetailType string `json:"detail-type"`
+	Time       string `json:"time"`
+}
+
+// ScheduledHandler adapts fn into a Handler for EventBridge scheduled events.
+// fn receives the time the schedule fired rather than time.Now, so that late or
+// backfilled runs still process the intended period.
+func ScheduledHandler(fn func(ctx context.Context, t time.Time) error) Handler {
+	return func(ctx context.Context, event json.RawMessage) (any, error) {
+		var e scheduledEvent
+		if err := unmarshal(event, &e); err != nil {
+			return nil, newDecodeError(&e, event, err)
+		}
+		if e.DetailType != scheduledDetailType {
+			return nil, fmt.Errorf("%w: detail-type is %q", ErrNotScheduledEvent, e.DetailType)
+		}
+		t, err := time.Parse(time.RFC3339, e.Time)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid time %q: %v", ErrNotScheduledEvent, e.Time, err)
+		}
+		return nil, fn(ctx, t)
+	}
+}
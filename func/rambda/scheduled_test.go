@@ -0,0 +1,34 @@
+package rambda
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestScheduledHandler(t *testing.T) {
+	var fired time.Time
+	h := ScheduledHandler(func(ctx context.Context, t time.Time) error {
+		fired = t
+		return nil
+	})
+
+	payload := `{"id":"1","detail-type":"Scheduled Event","source":"aws.events","time":"2024-03-01T12:00:00Z","detail":{}}`
+	if _, err := h(context.Background(), json.RawMessage(payload)); err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC); !fired.Equal(want) {
+		t.Errorf("fired = %s, want %s", fired, want)
+	}
+
+	for _, payload := range []string{
+		`{"detail-type":"OrderPlaced","time":"2024-03-01T12:00:00Z"}`,
+		`{"detail-type":"Scheduled Event","time":"yesterday"}`,
+	} {
+		if _, err := h(context.Background(), json.RawMessage(payload)); !errors.Is(err, ErrNotScheduledEvent) {
+			t.Errorf("%s: error = %v, want ErrNotScheduledEvent", payload, err)
+		}
+	}
+}
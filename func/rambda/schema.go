@@ -0,0 +1,222 @@
+package rambda
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JSONSchemaDraft is the dialect of the documents generated by Schema.
+const JSONSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema is a JSON Schema document or subschema.
+type JSONSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Ref                  string                 `json:"$ref,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	ContentEncoding      string                 `json:"contentEncoding,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	AdditionalProperties *JSONSchema            `json:"additionalProperties,omitempty"`
+	MinLength            *int                   `json:"minLength,omitempty"`
+	MaxLength            *int                   `json:"maxLength,omitempty"`
+	MinItems             *int                   `json:"minItems,omitempty"`
+	MaxItems             *int                   `json:"maxItems,omitempty"`
+	Minimum              *float64               `json:"minimum,omitempty"`
+	Maximum              *float64               `json:"maximum,omitempty"`
+	Defs                 map[string]*JSONSchema `json:"$defs,omitempty"`
+}
+
+// HandlerSchema describes the event and result types of a handler.
+type HandlerSchema struct {
+	Input  *JSONSchema `json:"input"`
+	Output *JSONSchema `json:"output"`
+}
+
+// Schema generates JSON Schema documents for the In and Out types of a function that can
+// be passed to Start or Typed.
+//
+// Field names and optionality follow the json tags: fields are required unless they are
+// pointers or tagged omitempty, or tagged validate:"required". The min, max and email
+// validate rules become the matching keywords. Named structs are emitted once under
+// $defs, keyed by their package-qualified name, and referenced, which also covers
+// self-referential types.
+func Schema[In any, Out any](fn func(context.Context, In) (Out, error)) HandlerSchema {
+	return HandlerSchema{
+		Input:  schemaFor(reflect.TypeFor[In]()),
+		Output: schemaFor(reflect.TypeFor[Out]()),
+	}
+}
+
+func schemaFor(t reflect.Type) *JSONSchema {
+	g := &schemaGenerator{defs: map[string]*JSONSchema{}}
+	doc := g.schema(t)
+	doc.Schema = JSONSchemaDraft
+	if len(g.defs) > 0 {
+		doc.Defs = g.defs
+	}
+	return doc
+}
+
+type schemaGenerator struct {
+	defs map[string]*JSONSchema
+}
+
+var (
+	timeType       = reflect.TypeFor[time.Time]()
+	rawMessageType = reflect.TypeFor[json.RawMessage]()
+)
+
+func (g *schemaGenerator) schema(t reflect.Type) *JSONSchema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t == timeType:
+		return &JSONSchema{Type: "string", Format: "date-time"}
+	case t == rawMessageType:
+		return &JSONSchema{}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &JSONSchema{Type: "string"}
+	case reflect.Bool:
+		return &JSONSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &JSONSchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &JSONSchema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 && t.Kind() == reflect.Slice {
+			return &JSONSchema{Type: "string", ContentEncoding: "base64"}
+		}
+		return &JSONSchema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &JSONSchema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		name := defName(t)
+		if _, ok := g.defs[name]; !ok {
+			// 自己参照で無限に再帰しないよう、生成前に登録しておく
+			g.defs[name] = &JSONSchema{}
+			*g.defs[name] = *g.structSchema(t)
+		}
+		return &JSONSchema{Ref: "#/$defs/" + jsonPointerEscaper.Replace(name)}
+	}
+	// interface など型の決まらない値は何でも受け付ける
+	return &JSONSchema{}
+}
+
+// jsonPointerEscaper escapes a $defs key for use as a JSON Pointer token (RFC 6901).
+var jsonPointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// defName returns the $defs key of a named type. It is qualified with the package path
+// so that same-named types from different packages do not collide, and characters that
+// are not allowed in a URI fragment, such as the brackets of generic instantiations,
+// are replaced with '_'.
+func defName(t reflect.Type) string {
+	name := t.Name()
+	if t.PkgPath() != "" {
+		name = t.PkgPath() + "." + name
+	}
+	return strings.Map(func(r rune) rune {
+		switch {
+		case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', '0' <= r && r <= '9',
+			r == '.', r == '_', r == '-', r == '~', r == '/':
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+func (g *schemaGenerator) structSchema(t reflect.Type) *JSONSchema {
+	s := &JSONSchema{Type: "object", Properties: map[string]*JSONSchema{}}
+	g.addFields(s, t)
+	return s
+}
+
+func (g *schemaGenerator) addFields(s *JSONSchema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, flags, _ := strings.Cut(tag, ",")
+
+		// タグのない埋め込み構造体のフィールドは encoding/json と同じく親に展開する
+		if sf.Anonymous && name == "" {
+			ft := sf.Type
+			for ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				g.addFields(s, ft)
+				continue
+			}
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+
+		prop := g.schema(sf.Type)
+		rules := parseRules(sf.Tag.Get("validate"))
+		required := sf.Type.Kind() != reflect.Pointer && !strings.Contains(","+flags+",", ",omitempty,")
+		for _, r := range rules {
+			if r.name == "required" {
+				required = true
+			}
+			applyRule(prop, r)
+		}
+
+		s.Properties[name] = prop
+		if required {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+func applyRule(s *JSONSchema, r rule) {
+	switch r.name {
+	case "email":
+		s.Format = "email"
+	case "min", "max":
+		limit, err := strconv.ParseFloat(r.param, 64)
+		if err != nil {
+			return
+		}
+		n := int(limit)
+		switch s.Type {
+		case "string":
+			if r.name == "min" {
+				s.MinLength = &n
+			} else {
+				s.MaxLength = &n
+			}
+		case "array":
+			if r.name == "min" {
+				s.MinItems = &n
+			} else {
+				s.MaxItems = &n
+			}
+		case "integer", "number":
+			if r.name == "min" {
+				s.Minimum = &limit
+			} else {
+				s.Maximum = &limit
+			}
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package rambda
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"testing"
+	"time"
+)
+
+type schemaNode struct {
+	Name     string        `json:"name"`
+	Children []*schemaNode `json:"children,omitempty"`
+}
+
+type schemaAudit struct {
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type schemaOrder struct {
+	schemaAudit
+	ID       string            `json:"id" validate:"required,min=3"`
+	Email    string            `json:"email,omitempty" validate:"email"`
+	Quantity int               `json:"quantity" validate:"min=1,max=10"`
+	Note     *string           `json:"note"`
+	Tags     []string          `json:"tags" validate:"max=5"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Tree     schemaNode        `json:"tree"`
+	Internal string            `json:"-"`
+	secret   string
+}
+
+func TestSchema(t *testing.T) {
+	s := Schema(func(ctx context.Context, in schemaOrder) ([]byte, error) { return nil, nil })
+
+	got, err := json.Marshal(s.Input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"$schema":"https://json-schema.org/draft/2020-12/schema","$ref":"#/$defs/function~1rambda.schemaOrder","$defs":{` +
+		`"function/rambda.schemaNode":{"type":"object","properties":{"children":{"type":"array","items":{"$ref":"#/$defs/function~1rambda.schemaNode"}},"name":{"type":"string"}},"required":["name"]},` +
+		`"function/rambda.schemaOrder":{"type":"object","properties":{` +
+		`"createdAt":{"type":"string","format":"date-time"},` +
+		`"email":{"type":"string","format":"email"},` +
+		`"id":{"type":"string","minLength":3},` +
+		`"labels":{"type":"object","additionalProperties":{"type":"string"}},` +
+		`"note":{"type":"string"},` +
+		`"quantity":{"type":"integer","minimum":1,"maximum":10},` +
+		`"tags":{"type":"array","items":{"type":"string"},"maxItems":5},` +
+		`"tree":{"$ref":"#/$defs/function~1rambda.schemaNode"}},` +
+		`"required":["createdAt","id","quantity","tags","tree"]}}}`
+	if string(got) != want {
+		t.Errorf("input schema =\n%s\nwant\n%s", got, want)
+	}
+
+	out, _ := json.Marshal(s.Output)
+	if string(out) != `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"string","contentEncoding":"base64"}` {
+		t.Errorf("output schema = %s", out)
+	}
+}
+
+// Userinfo shares its name with url.Userinfo.
+type Userinfo struct {
+	Login string `json:"login"`
+}
+
+type schemaPair[T any] struct {
+	Value T `json:"value"`
+}
+
+type schemaAccount struct {
+	Local  Userinfo               `json:"local"`
+	Remote *url.Userinfo          `json:"remote"`
+	Pair   schemaPair[schemaNode] `json:"pair"`
+}
+
+func TestSchemaDefsQualified(t *testing.T) {
+	s := Schema(func(ctx context.Context, in schemaAccount) (string, error) { return "", nil })
+
+	want := map[string]string{
+		"local":  "#/$defs/function~1rambda.Userinfo",
+		"remote": "#/$defs/net~1url.Userinfo",
+		"pair":   "#/$defs/function~1rambda.schemaPair_function~1rambda.schemaNode_",
+	}
+	account := s.Input.Defs["function/rambda.schemaAccount"]
+	if account == nil {
+		t.Fatalf("missing schemaAccount in $defs: %v", s.Input.Defs)
+	}
+	for field, ref := range want {
+		if got := account.Properties[field].Ref; got != ref {
+			t.Errorf("%s $ref = %q, want %q", field, got, ref)
+		}
+	}
+	if local := s.Input.Defs["function/rambda.Userinfo"]; local == nil || local.Properties["login"] == nil {
+		t.Errorf("local Userinfo schema = %+v", local)
+	}
+	if _, ok := s.Input.Defs["net/url.Userinfo"]; !ok {
+		t.Error("missing url.Userinfo in $defs")
+	}
+}
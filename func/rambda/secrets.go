@@ -0,0 +1,165 @@
+package rambda
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+)
+
+// SecretsManagerClient is the subset of the Secrets Manager API used by ResolveSecrets.
+type SecretsManagerClient interface {
+	GetSecretValue(ctx context.Context, params *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error)
+}
+
+// SSMClient is the subset of the SSM API used by ResolveSecrets.
+type SSMClient interface {
+	GetParameter(ctx context.Context, params *ssm.GetParameterInput, optFns ...func(*ssm.Options)) (*ssm.GetParameterOutput, error)
+}
+
+const secretsManagerARNPrefix = "arn:aws:secretsmanager:"
+
+var (
+	secretCacheMu sync.Mutex
+	secretCache   = map[string]string{}
+)
+
+// ResolveSecrets replaces every string field tagged `secret:"true"` in the struct pointed
+// to by cfg with the plaintext it references, typically after LoadConfig:
+//
+//	DBPassword string `env:"DB_PASSWORD_ARN,required" secret:"true"`
+//
+// Values starting with "arn:aws:secretsmanager:" are read from Secrets Manager and any
+// other value is read from Parameter Store with decryption. Fetched secrets are cached for
+// the container lifetime. A failure to fetch a field whose env tag is required is returned;
+// other fields that cannot be fetched are cleared. Empty fields are left as they are.
+func ResolveSecrets(ctx context.Context, cfg any, opts ...Option) error {
+	rv := reflect.ValueOf(cfg)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("rambda: ResolveSecrets requires a pointer to a struct, got %T", cfg)
+	}
+	r := &secretResolver{opts: newOptions(opts)}
+	return r.resolveStruct(ctx, rv.Elem())
+}
+
+type secretResolver struct {
+	opts *options
+
+	cfgOnce sync.Once
+	cfg     aws.Config
+	cfgErr  error
+}
+
+func (r *secretResolver) resolveStruct(ctx context.Context, rv reflect.Value) error {
+	var errs []error
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		fv := rv.Field(i)
+		if fv.Kind() == reflect.Struct {
+			if err := r.resolveStruct(ctx, fv); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		if sf.Tag.Get("secret") != "true" || fv.Kind() != reflect.String || fv.String() == "" {
+			continue
+		}
+
+		ref := fv.String()
+		value, err := r.fetch(ctx, ref)
+		if err != nil {
+			_, flags, _ := strings.Cut(sf.Tag.Get("env"), ",")
+			if flags == "required" {
+				errs = append(errs, fmt.Errorf("rambda: resolve secret %s (%s): %w", sf.Name, ref, err))
+			}
+			value = ""
+		}
+		fv.SetString(value)
+	}
+	return errors.Join(errs...)
+}
+
+func (r *secretResolver) fetch(ctx context.Context, ref string) (string, error) {
+	secretCacheMu.Lock()
+	value, ok := secretCache[ref]
+	secretCacheMu.Unlock()
+	if ok {
+		return value, nil
+	}
+
+	var err error
+	if strings.HasPrefix(ref, secretsManagerARNPrefix) {
+		value, err = r.fetchSecret(ctx, ref)
+	} else {
+		value, err = r.fetchParameter(ctx, ref)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	secretCacheMu.Lock()
+	secretCache[ref] = value
+	secretCacheMu.Unlock()
+	return value, nil
+}
+
+func (r *secretResolver) fetchSecret(ctx context.Context, arn string) (string, error) {
+	client := r.opts.secretsClient
+	if client == nil {
+		cfg, err := r.awsConfig(ctx)
+		if err != nil {
+			return "", err
+		}
+		client = secretsmanager.NewFromConfig(cfg)
+		r.opts.secretsClient = client
+	}
+	out, err := client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String(arn)})
+	if err != nil {
+		return "", err
+	}
+	if out.SecretString != nil {
+		return *out.SecretString, nil
+	}
+	return string(out.SecretBinary), nil
+}
+
+func (r *secretResolver) fetchParameter(ctx context.Context, name string) (string, error) {
+	client := r.opts.ssmClient
+	if client == nil {
+		cfg, err := r.awsConfig(ctx)
+		if err != nil {
+			return "", err
+		}
+		client = ssm.NewFromConfig(cfg)
+		r.opts.ssmClient = client
+	}
+	out, err := client.GetParameter(ctx, &ssm.GetParameterInput{Name: aws.String(name), WithDecryption: aws.Bool(true)})
+	if err != nil {
+		return "", err
+	}
+	if out.Parameter == nil {
+		return "", fmt.Errorf("parameter %s has no value", name)
+	}
+	return aws.ToString(out.Parameter.Value), nil
+}
+
+func (r *secretResolver) awsConfig(ctx context.Context) (aws.Config, error) {
+	r.cfgOnce.Do(func() {
+		r.cfg, r.cfgErr = config.LoadDefaultConfig(ctx)
+		if r.cfgErr != nil {
+			r.cfgErr = fmt.Errorf("rambda: load aws config: %w", r.cfgErr)
+		}
+	})
+	return r.cfg, r.cfgErr
+}
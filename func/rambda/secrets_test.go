@@ -0,0 +1,91 @@
+package rambda
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	ssmtypes "github.com/aws/aws-sdk-go-v2/service/ssm/types"
+)
+
+type fakeSecrets struct {
+	values map[string]string
+	calls  int
+}
+
+func (f *fakeSecrets) GetSecretValue(ctx context.Context, params *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error) {
+	f.calls++
+	v, ok := f.values[aws.ToString(params.SecretId)]
+	if !ok {
+		return nil, errors.New("ResourceNotFoundException")
+	}
+	return &secretsmanager.GetSecretValueOutput{SecretString: aws.String(v)}, nil
+}
+
+func (f *fakeSecrets) GetParameter(ctx context.Context, params *ssm.GetParameterInput, optFns ...func(*ssm.Options)) (*ssm.GetParameterOutput, error) {
+	f.calls++
+	if !aws.ToBool(params.WithDecryption) {
+		return nil, errors.New("expected decryption")
+	}
+	v, ok := f.values[aws.ToString(params.Name)]
+	if !ok {
+		return nil, errors.New("ParameterNotFound")
+	}
+	return &ssm.GetParameterOutput{Parameter: &ssmtypes.Parameter{Value: aws.String(v)}}, nil
+}
+
+func resetSecretCache() {
+	secretCacheMu.Lock()
+	secretCache = map[string]string{}
+	secretCacheMu.Unlock()
+}
+
+func TestResolveSecrets(t *testing.T) {
+	resetSecretCache()
+	t.Cleanup(resetSecretCache)
+
+	const arn = "arn:aws:secretsmanager:ap-northeast-1:123456789012:secret:db-AbCdEf"
+	fake := &fakeSecrets{values: map[string]string{arn: "s3cr3t", "/app/api-key": "key-1"}}
+	type config struct {
+		DBPassword string `env:"DB_PASSWORD,required" secret:"true"`
+		APIKey     string `env:"API_KEY" secret:"true"`
+		Optional   string `env:"OPTIONAL" secret:"true"`
+		Table      string `env:"TABLE"`
+	}
+
+	cfg := config{DBPassword: arn, APIKey: "/app/api-key", Optional: "/app/missing", Table: "/not/a/secret"}
+	opts := []Option{WithSecretsManagerClient(fake), WithSSMClient(fake)}
+	if err := ResolveSecrets(context.Background(), &cfg, opts...); err != nil {
+		t.Fatal(err)
+	}
+	want := config{DBPassword: "s3cr3t", APIKey: "key-1", Table: "/not/a/secret"}
+	if cfg != want {
+		t.Errorf("config = %+v, want %+v", cfg, want)
+	}
+
+	calls := fake.calls
+	cfg = config{DBPassword: arn}
+	if err := ResolveSecrets(context.Background(), &cfg, opts...); err != nil || cfg.DBPassword != "s3cr3t" {
+		t.Fatalf("second resolve = %+v, %v", cfg, err)
+	}
+	if fake.calls != calls {
+		t.Error("resolved secrets should be cached")
+	}
+}
+
+func TestResolveSecretsRequiredFailure(t *testing.T) {
+	resetSecretCache()
+	t.Cleanup(resetSecretCache)
+
+	cfg := struct {
+		DBPassword string `env:"DB_PASSWORD,required" secret:"true"`
+	}{DBPassword: "/app/missing"}
+	err := ResolveSecrets(context.Background(), &cfg, WithSSMClient(&fakeSecrets{}))
+	if err == nil || !strings.Contains(err.Error(), "DBPassword (/app/missing): ParameterNotFound") {
+		t.Errorf("error = %v", err)
+	}
+}
@@ -0,0 +1,105 @@
+package rambda
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+const defaultShutdownTimeout = 500 * time.Millisecond
+
+var (
+	shutdownMu      sync.Mutex
+	shutdownHooks   []func(context.Context)
+	shutdownTimeout = defaultShutdownTimeout
+	shutdownOnce    sync.Once
+
+	// exit is replaced in tests.
+	exit = os.Exit
+)
+
+// OnShutdown registers fn to run when the process receives SIGTERM, before it exits.
+// All registered callbacks run concurrently and share a context that is cancelled when
+// the shutdown window set by SetShutdownTimeout (500ms by default) ends; the process exits
+// once every callback has returned or the window has closed. A panicking callback is
+// logged and does not stop the others.
+//
+// Lambda only sends SIGTERM to functions that have at least one extension registered.
+// Start registers the internal extension needed for this when shutdown callbacks exist;
+// when calling lambda.Start directly, pass lambda.WithEnableSIGTERM() to lambda.StartWithOptions.
+func OnShutdown(fn func(ctx context.Context)) {
+	shutdownMu.Lock()
+	shutdownHooks = append(shutdownHooks, fn)
+	shutdownMu.Unlock()
+
+	shutdownOnce.Do(func() {
+		signaled := make(chan os.Signal, 1)
+		signal.Notify(signaled, syscall.SIGTERM)
+		go func() {
+			<-signaled
+			runShutdownHooks()
+			exit(0)
+		}()
+	})
+}
+
+// SetShutdownTimeout sets how long shutdown callbacks may run.
+func SetShutdownTimeout(d time.Duration) {
+	shutdownMu.Lock()
+	defer shutdownMu.Unlock()
+	shutdownTimeout = d
+}
+
+func hasShutdownHooks() bool {
+	shutdownMu.Lock()
+	defer shutdownMu.Unlock()
+	return len(shutdownHooks) > 0
+}
+
+// startOptions returns the lambda options Start needs for the registered features.
+func startOptions() []lambda.Option {
+	if hasShutdownHooks() {
+		return []lambda.Option{lambda.WithEnableSIGTERM()}
+	}
+	return nil
+}
+
+func runShutdownHooks() {
+	shutdownMu.Lock()
+	hooks := append([]func(context.Context){}, shutdownHooks...)
+	timeout := shutdownTimeout
+	shutdownMu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for _, hook := range hooks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() {
+				if v := recover(); v != nil {
+					slog.Error("rambda: shutdown callback panicked", slog.Any("panic", v))
+				}
+			}()
+			hook(ctx)
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,73 @@
+package rambda
+
+import (
+	"context"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func resetShutdown(t *testing.T) {
+	t.Helper()
+	shutdownMu.Lock()
+	prevHooks, prevTimeout := shutdownHooks, shutdownTimeout
+	shutdownHooks = nil
+	shutdownMu.Unlock()
+	t.Cleanup(func() {
+		shutdownMu.Lock()
+		shutdownHooks, shutdownTimeout = prevHooks, prevTimeout
+		shutdownMu.Unlock()
+	})
+}
+
+func TestRunShutdownHooks(t *testing.T) {
+	resetShutdown(t)
+	SetShutdownTimeout(50 * time.Millisecond)
+
+	var ran atomic.Int32
+	shutdownHooks = []func(context.Context){
+		func(ctx context.Context) { panic("boom") },
+		func(ctx context.Context) { ran.Add(1) },
+		func(ctx context.Context) {
+			<-ctx.Done()
+			ran.Add(1)
+		},
+	}
+
+	start := time.Now()
+	runShutdownHooks()
+	if elapsed := time.Since(start); elapsed > 200*time.Millisecond {
+		t.Errorf("shutdown took %s, want it bounded by the timeout", elapsed)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if ran.Load() != 2 {
+		t.Errorf("%d callbacks ran, want 2", ran.Load())
+	}
+}
+
+func TestOnShutdownSIGTERM(t *testing.T) {
+	resetShutdown(t)
+	exited := make(chan int, 1)
+	prevExit := exit
+	exit = func(code int) { exited <- code }
+	t.Cleanup(func() { exit = prevExit })
+
+	called := make(chan struct{})
+	OnShutdown(func(ctx context.Context) { close(called) })
+	if !hasShutdownHooks() || len(startOptions()) != 1 {
+		t.Error("Start should enable SIGTERM when callbacks are registered")
+	}
+
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGTERM); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-called:
+	case <-time.After(time.Second):
+		t.Fatal("callback did not run")
+	}
+	if code := <-exited; code != 0 {
+		t.Errorf("exit code = %d", code)
+	}
+}
@@ -0,0 +1,127 @@
+package rambda
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// SNSMessage is an SNS notification with its message decoded into T.
+type SNSMessage[T any] struct {
+	MessageID string
+	TopicArn  string
+	Subject   string
+	Timestamp time.Time
+	// Message is the decoded payload. When T is string it holds the raw message.
+	Message T
+	// Attributes maps each message attribute name to its value.
+	Attributes map[string]string
+}
+
+// snsNotification is the notification SNS delivers, both in Lambda records and in SQS bodies.
+type snsNotification struct {
+	Type              string    `json:"Type"`
+	MessageID         string    `json:"MessageId"`
+	TopicArn          string    `json:"TopicArn"`
+	Subject           string    `json:"Subject"`
+	Timestamp         time.Time `json:"Timestamp"`
+	Message           string    `json:"Message"`
+	MessageAttributes map[string]struct {
+		Value string `json:"Value"`
+	} `json:"MessageAttributes"`
+}
+
+// SNSHandler adapts a per-message function into a Handler for SNS notifications.
+//
+// Records of SNS events are processed in order and the first error is returned.
+// SQS events from a queue subscribed to a topic are supported too, with or without raw
+// message delivery; they are processed like SQSHandler does, with fn's errors reported
+// as batch item failures and opts applied.
+func SNSHandler[T any](fn func(ctx context.Context, msg SNSMessage[T]) error, opts ...Option) Handler {
+	sqsHandler := SQSHandler(func(ctx context.Context, m events.SQSMessage) error {
+		msg, err := snsFromSQS[T](m)
+		if err != nil {
+			return err
+		}
+		return fn(ctx, msg)
+	}, opts...)
+
+	return func(ctx context.Context, event json.RawMessage) (any, error) {
+		if DetectSource(event) == SourceSQS {
+			return sqsHandler(ctx, event)
+		}
+
+		var snsEvent struct {
+			Records []struct {
+				SNS snsNotification `json:"Sns"`
+			} `json:"Records"`
+		}
+		if err := unmarshal(event, &snsEvent); err != nil {
+			return nil, newDecodeError(&snsEvent, event, err)
+		}
+		for _, r := range snsEvent.Records {
+			msg, err := newSNSMessage[T](r.SNS)
+			if err != nil {
+				return nil, err
+			}
+			if err := fn(ctx, msg); err != nil {
+				return nil, fmt.Errorf("rambda: sns message %s: %w", r.SNS.MessageID, err)
+			}
+		}
+		return nil, nil
+	}
+}
+
+func newSNSMessage[T any](n snsNotification) (SNSMessage[T], error) {
+	msg := SNSMessage[T]{
+		MessageID:  n.MessageID,
+		TopicArn:   n.TopicArn,
+		Subject:    n.Subject,
+		Timestamp:  n.Timestamp,
+		Attributes: make(map[string]string, len(n.MessageAttributes)),
+	}
+	for name, attr := range n.MessageAttributes {
+		msg.Attributes[name] = attr.Value
+	}
+	if err := decodeSNSPayload(n.Message, &msg.Message); err != nil {
+		return msg, err
+	}
+	return msg, nil
+}
+
+// snsFromSQS unwraps the SNS notification in an SQS body. With raw message delivery the
+// body is the message itself and the attributes are SQS message attributes.
+func snsFromSQS[T any](m events.SQSMessage) (SNSMessage[T], error) {
+	var n snsNotification
+	if unmarshal([]byte(m.Body), &n) == nil && n.Type == "Notification" && n.TopicArn != "" {
+		return newSNSMessage[T](n)
+	}
+
+	msg := SNSMessage[T]{
+		MessageID:  m.MessageId,
+		Attributes: make(map[string]string, len(m.MessageAttributes)),
+	}
+	for name, attr := range m.MessageAttributes {
+		if attr.StringValue != nil {
+			msg.Attributes[name] = *attr.StringValue
+		}
+	}
+	if err := decodeSNSPayload(m.Body, &msg.Message); err != nil {
+		return msg, err
+	}
+	return msg, nil
+}
+
+func decodeSNSPayload[T any](payload string, dst *T) error {
+	if s, ok := any(dst).(*string); ok {
+		*s = payload
+		return nil
+	}
+	if err := unmarshal([]byte(payload), dst); err != nil {
+		return newDecodeError(dst, []byte(payload), err)
+	}
+	return nil
+}
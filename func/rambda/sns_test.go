@@ -0,0 +1,90 @@
+package rambda
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+type userCreated struct {
+	UserID string `json:"userId"`
+}
+
+const snsNotificationJSON = `{"Type":"Notification","MessageId":"m-1","TopicArn":"arn:aws:sns:ap-northeast-1:123456789012:users","Subject":"created","Message":"{\"userId\":\"u-1\"}","MessageAttributes":{"tenant":{"Type":"String","Value":"acme"}}}`
+
+func TestSNSHandler(t *testing.T) {
+	var got []SNSMessage[userCreated]
+	h := SNSHandler(func(ctx context.Context, msg SNSMessage[userCreated]) error {
+		got = append(got, msg)
+		return nil
+	})
+
+	event := `{"Records":[{"EventSource":"aws:sns","Sns":` + snsNotificationJSON + `}]}`
+	if _, err := h(context.Background(), json.RawMessage(event)); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("got %d messages, want 1", len(got))
+	}
+	msg := got[0]
+	if msg.Message.UserID != "u-1" || msg.Subject != "created" || msg.Attributes["tenant"] != "acme" {
+		t.Errorf("message = %+v", msg)
+	}
+}
+
+func TestSNSHandlerRawString(t *testing.T) {
+	var got string
+	h := SNSHandler(func(ctx context.Context, msg SNSMessage[string]) error {
+		got = msg.Message
+		return nil
+	})
+	event := `{"Records":[{"EventSource":"aws:sns","Sns":{"MessageId":"m-1","Message":"plain text"}}]}`
+	if _, err := h(context.Background(), json.RawMessage(event)); err != nil {
+		t.Fatal(err)
+	}
+	if got != "plain text" {
+		t.Errorf("message = %q", got)
+	}
+}
+
+func TestSNSHandlerViaSQS(t *testing.T) {
+	var got []SNSMessage[userCreated]
+	h := SNSHandler(func(ctx context.Context, msg SNSMessage[userCreated]) error {
+		got = append(got, msg)
+		if msg.Message.UserID == "bad" {
+			return errors.New("failed")
+		}
+		return nil
+	})
+
+	tenant := "globex"
+	e := events.SQSEvent{Records: []events.SQSMessage{
+		{MessageId: "q-1", EventSource: "aws:sqs", Body: snsNotificationJSON},
+		{MessageId: "q-2", EventSource: "aws:sqs", Body: `{"userId":"u-2"}`,
+			MessageAttributes: map[string]events.SQSMessageAttribute{"tenant": {StringValue: &tenant, DataType: "String"}}},
+		{MessageId: "q-3", EventSource: "aws:sqs", Body: strconv.Quote("not json")},
+		{MessageId: "q-4", EventSource: "aws:sqs", Body: `{"userId":"bad"}`},
+	}}
+	payload, _ := json.Marshal(e)
+
+	res, err := h(context.Background(), payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := failureIDs(res.(events.SQSEventResponse)); len(ids) != 2 || ids[0] != "q-3" || ids[1] != "q-4" {
+		t.Errorf("failures = %v, want [q-3 q-4]", ids)
+	}
+	if len(got) != 3 {
+		t.Fatalf("got %d messages, want 3", len(got))
+	}
+	if got[0].Message.UserID != "u-1" || got[0].Attributes["tenant"] != "acme" || got[0].MessageID != "m-1" {
+		t.Errorf("wrapped message = %+v", got[0])
+	}
+	if got[1].Message.UserID != "u-2" || got[1].Attributes["tenant"] != "globex" {
+		t.Errorf("raw delivery message = %+v", got[1])
+	}
+}
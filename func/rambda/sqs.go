@@ -0,0 +1,141 @@
+package rambda
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+// SQSClient is the subset of the SQS API used for dead-letter forwarding.
+type SQSClient interface {
+	SendMessage(ctx context.Context, params *sqs.SendMessageInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageOutput, error)
+}
+
+// Message attributes added to messages forwarded with WithDeadLetter.
+const (
+	AttrOriginalError = "original-error"
+	AttrFailureTime   = "failure-time"
+	// AttrOriginalAttributes holds the message's own attributes as JSON when they do not
+	// fit next to the two above.
+	AttrOriginalAttributes = "original-attributes"
+)
+
+// maxMessageAttributes is the SQS limit on message attributes per message.
+const maxMessageAttributes = 10
+
+// SQSHandler adapts a per-message function into a Handler for SQS batches.
+//
+// The returned events.SQSEventResponse lists the messageId of every message for which fn
+// returned an error or panicked, so that with ReportBatchItemFailures enabled only those are retried.
+// Use WithConcurrency to process messages in parallel.
+//
+// Errors marked with Retryable are retried in place as configured by the Retry options.
+// With WithDeadLetter, a message that still fails is sent to the dead-letter queue and is
+// only reported as a failure if that send fails too.
+//
+// When the invocation has a deadline, messages run with a context that ends the time set
+// with WithTimeBuffer before it. Once that budget is used up no further messages are
+// started and the rest are reported as failures, so that they are retried rather than
+// lost when Lambda stops the function. A message that fails after the budget ran out is
+// reported too but never sent to the dead-letter queue.
+//
+// SQSHandler panics if WithDeadLetter is given a nil SQSClient.
+func SQSHandler(fn func(ctx context.Context, msg events.SQSMessage) error, opts ...Option) Handler {
+	o := newOptions(opts)
+	if o.deadLetterURL != "" && o.sqsClient == nil {
+		panic("rambda: WithDeadLetter requires a non-nil SQSClient")
+	}
+	return func(ctx context.Context, event json.RawMessage) (any, error) {
+		var sqsEvent events.SQSEvent
+		if err := unmarshal(event, &sqsEvent); err != nil {
+			return nil, newDecodeError(&sqsEvent, event, err)
+		}
+
+		budget := ctx
+		if deadline, ok := ctx.Deadline(); ok && o.timeBuffer > 0 {
+			var cancel context.CancelFunc
+			budget, cancel = context.WithDeadline(ctx, deadline.Add(-o.timeBuffer))
+			defer cancel()
+		}
+
+		failed := processBatch(len(sqsEvent.Records), o.concurrency, func(i int) error {
+			msg := sqsEvent.Records[i]
+			if err := budget.Err(); err != nil {
+				recordBatchError(ctx, msg.MessageId, err)
+				return err
+			}
+			err := safeCall(func() error {
+				return Retry(budget, func() error { return fn(budget, msg) }, opts...)
+			})
+			if err != nil {
+				recordBatchError(ctx, msg.MessageId, err)
+			}
+			// 時間切れで失敗したメッセージは DLQ に送らず再配信に任せる
+			if err == nil || o.deadLetterURL == "" || budget.Err() != nil {
+				return err
+			}
+			return sendDeadLetter(ctx, o, msg, err)
+		})
+
+		res := events.SQSEventResponse{
+			BatchItemFailures: make([]events.SQSBatchItemFailure, 0, len(failed)),
+		}
+		for _, i := range failed {
+			res.BatchItemFailures = append(res.BatchItemFailures, events.SQSBatchItemFailure{
+				ItemIdentifier: sqsEvent.Records[i].MessageId,
+			})
+		}
+		return res, nil
+	}
+}
+
+// sendDeadLetter forwards msg to the dead-letter queue with the error that made it fail.
+// Its attributes are folded into AttrOriginalAttributes when they would exceed the SQS limit.
+func sendDeadLetter(ctx context.Context, o *options, msg events.SQSMessage, cause error) error {
+	attrs := make(map[string]types.MessageAttributeValue, min(len(msg.MessageAttributes), maxMessageAttributes-2)+2)
+	if len(msg.MessageAttributes)+2 <= maxMessageAttributes {
+		for name, v := range msg.MessageAttributes {
+			attrs[name] = types.MessageAttributeValue{
+				DataType:    aws.String(v.DataType),
+				StringValue: v.StringValue,
+				BinaryValue: v.BinaryValue,
+			}
+		}
+	} else {
+		// 上限を超えると SendMessage が失敗するので、元の属性は JSON にまとめて 1 つに収める
+		b, err := marshal(msg.MessageAttributes)
+		if err != nil {
+			return err
+		}
+		attrs[AttrOriginalAttributes] = types.MessageAttributeValue{
+			DataType:    aws.String("String"),
+			StringValue: aws.String(string(b)),
+		}
+	}
+	attrs[AttrOriginalError] = types.MessageAttributeValue{
+		DataType:    aws.String("String"),
+		StringValue: aws.String(cause.Error()),
+	}
+	attrs[AttrFailureTime] = types.MessageAttributeValue{
+		DataType:    aws.String("String"),
+		StringValue: aws.String(time.Now().UTC().Format(time.RFC3339)),
+	}
+
+	input := &sqs.SendMessageInput{
+		QueueUrl:          aws.String(o.deadLetterURL),
+		MessageBody:       aws.String(msg.Body),
+		MessageAttributes: attrs,
+	}
+	// FIFO キューへの転送にはグループ ID が必須
+	if group := msg.Attributes["MessageGroupId"]; group != "" {
+		input.MessageGroupId = aws.String(group)
+		input.MessageDeduplicationId = aws.String(msg.MessageId)
+	}
+	_, err := o.sqsClient.SendMessage(ctx, input)
+	return err
+}
@@ -0,0 +1,261 @@
+package rambda
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+)
+
+func sqsEventPayload(t *testing.T, ids ...string) json.RawMessage {
+	t.Helper()
+	var e events.SQSEvent
+	for _, id := range ids {
+		e.Records = append(e.Records, events.SQSMessage{MessageId: id, Body: id, EventSource: "aws:sqs"})
+	}
+	b, err := json.Marshal(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func failureIDs(res events.SQSEventResponse) []string {
+	ids := make([]string, 0, len(res.BatchItemFailures))
+	for _, f := range res.BatchItemFailures {
+		ids = append(ids, f.ItemIdentifier)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func TestSQSHandlerPartialFailures(t *testing.T) {
+	h := SQSHandler(func(ctx context.Context, msg events.SQSMessage) error {
+		switch msg.Body {
+		case "bad":
+			return errors.New("failed")
+		case "panic":
+			panic("boom")
+		}
+		return nil
+	})
+
+	res, err := h(context.Background(), sqsEventPayload(t, "ok-1", "bad", "panic", "ok-2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := failureIDs(res.(events.SQSEventResponse))
+	if len(got) != 2 || got[0] != "bad" || got[1] != "panic" {
+		t.Errorf("failures = %v, want [bad panic]", got)
+	}
+}
+
+func TestSQSHandlerAllSucceed(t *testing.T) {
+	h := SQSHandler(func(ctx context.Context, msg events.SQSMessage) error { return nil })
+
+	res, err := h(context.Background(), sqsEventPayload(t, "a", "b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := json.Marshal(res)
+	if string(b) != `{"batchItemFailures":[]}` {
+		t.Errorf("response = %s", b)
+	}
+}
+
+func TestSQSHandlerConcurrency(t *testing.T) {
+	var ids []string
+	for i := 0; i < 50; i++ {
+		ids = append(ids, string(rune('a'+i%26))+string(rune('0'+i/26)))
+	}
+
+	var mu sync.Mutex
+	running, peak := 0, 0
+	h := SQSHandler(func(ctx context.Context, msg events.SQSMessage) error {
+		mu.Lock()
+		running++
+		if running > peak {
+			peak = running
+		}
+		mu.Unlock()
+		time.Sleep(time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+		if msg.Body[0] == 'a' {
+			return errors.New("failed")
+		}
+		return nil
+	}, WithConcurrency(4))
+
+	res, err := h(context.Background(), sqsEventPayload(t, ids...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := failureIDs(res.(events.SQSEventResponse))
+	if want := []string{"a0", "a1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("failures = %v, want %v", got, want)
+	}
+	if peak > 4 {
+		t.Errorf("peak concurrency = %d, want <= 4", peak)
+	}
+}
+
+type fakeSQS struct {
+	mu   sync.Mutex
+	sent []*sqs.SendMessageInput
+	err  error
+}
+
+func (f *fakeSQS) SendMessage(ctx context.Context, params *sqs.SendMessageInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.sent = append(f.sent, params)
+	return &sqs.SendMessageOutput{}, nil
+}
+
+func TestSQSHandlerDeadLetter(t *testing.T) {
+	attempts := map[string]int{}
+	fn := func(ctx context.Context, msg events.SQSMessage) error {
+		attempts[msg.Body]++
+		if msg.Body == "bad" {
+			return Retryable(errors.New("downstream unavailable"))
+		}
+		return nil
+	}
+	client := &fakeSQS{}
+	h := SQSHandler(fn, WithDeadLetter("https://sqs/dlq", client), WithMaxAttempts(2), WithBackoff(0, 0))
+
+	res, err := h(context.Background(), sqsEventPayload(t, "ok", "bad"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := failureIDs(res.(events.SQSEventResponse)); len(got) != 0 {
+		t.Errorf("failures = %v, want none after forwarding", got)
+	}
+	if attempts["bad"] != 2 {
+		t.Errorf("attempts = %d, want 2 before forwarding", attempts["bad"])
+	}
+	if len(client.sent) != 1 {
+		t.Fatalf("sent %d messages, want 1", len(client.sent))
+	}
+	in := client.sent[0]
+	if aws.ToString(in.QueueUrl) != "https://sqs/dlq" || aws.ToString(in.MessageBody) != "bad" {
+		t.Errorf("sent %q to %q", aws.ToString(in.MessageBody), aws.ToString(in.QueueUrl))
+	}
+	if got := aws.ToString(in.MessageAttributes[AttrOriginalError].StringValue); got != "rambda: giving up after 2 attempts: downstream unavailable" {
+		t.Errorf("original-error = %q", got)
+	}
+	if _, err := time.Parse(time.RFC3339, aws.ToString(in.MessageAttributes[AttrFailureTime].StringValue)); err != nil {
+		t.Errorf("failure-time: %v", err)
+	}
+}
+
+func TestSQSHandlerDeadLetterManyAttributes(t *testing.T) {
+	msg := events.SQSMessage{MessageId: "bad", Body: "bad", EventSource: "aws:sqs", MessageAttributes: map[string]events.SQSMessageAttribute{}}
+	for i := 0; i < maxMessageAttributes; i++ {
+		msg.MessageAttributes[fmt.Sprintf("attr%d", i)] = events.SQSMessageAttribute{DataType: "String", StringValue: aws.String(fmt.Sprint(i))}
+	}
+	payload, err := json.Marshal(events.SQSEvent{Records: []events.SQSMessage{msg}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &fakeSQS{}
+	h := SQSHandler(func(ctx context.Context, msg events.SQSMessage) error {
+		return errors.New("failed")
+	}, WithDeadLetter("https://sqs/dlq", client))
+
+	if _, err := h(context.Background(), payload); err != nil {
+		t.Fatal(err)
+	}
+	if len(client.sent) != 1 {
+		t.Fatalf("sent %d messages, want 1", len(client.sent))
+	}
+	attrs := client.sent[0].MessageAttributes
+	if len(attrs) > maxMessageAttributes {
+		t.Errorf("sent %d attributes, want at most %d", len(attrs), maxMessageAttributes)
+	}
+	if got := aws.ToString(attrs[AttrOriginalError].StringValue); got != "failed" {
+		t.Errorf("original-error = %q", got)
+	}
+	var original map[string]events.SQSMessageAttribute
+	if err := json.Unmarshal([]byte(aws.ToString(attrs[AttrOriginalAttributes].StringValue)), &original); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(original, msg.MessageAttributes) {
+		t.Errorf("original-attributes = %v, want %v", original, msg.MessageAttributes)
+	}
+}
+
+func TestSQSHandlerDeadLetterSendFails(t *testing.T) {
+	client := &fakeSQS{err: errors.New("access denied")}
+	h := SQSHandler(func(ctx context.Context, msg events.SQSMessage) error {
+		return errors.New("failed")
+	}, WithDeadLetter("https://sqs/dlq", client))
+
+	res, err := h(context.Background(), sqsEventPayload(t, "bad"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := failureIDs(res.(events.SQSEventResponse)); !reflect.DeepEqual(got, []string{"bad"}) {
+		t.Errorf("failures = %v, want [bad]", got)
+	}
+}
+
+func TestSQSHandlerDeadLetterNilClient(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected SQSHandler to panic on a nil dead-letter client")
+		}
+	}()
+	SQSHandler(func(ctx context.Context, msg events.SQSMessage) error {
+		return nil
+	}, WithDeadLetter("https://sqs/dlq", nil))
+}
+
+func TestSQSHandlerTimeBuffer(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	var processed []string
+	client := &fakeSQS{}
+	h := SQSHandler(func(ctx context.Context, msg events.SQSMessage) error {
+		processed = append(processed, msg.Body)
+		if msg.Body == "slow" {
+			// バジェットを使い切るまでブロックし、呼び出し途中のメッセージとして失敗させる
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		return nil
+	}, WithTimeBuffer(60*time.Millisecond), WithDeadLetter("https://sqs/dlq", client))
+
+	start := time.Now()
+	res, err := h(ctx, sqsEventPayload(t, "a", "slow", "b", "c"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 80*time.Millisecond {
+		t.Errorf("handler returned after %v, want before the buffer", elapsed)
+	}
+	if want := []string{"a", "slow"}; !reflect.DeepEqual(processed, want) {
+		t.Errorf("processed = %v, want %v", processed, want)
+	}
+	if got, want := failureIDs(res.(events.SQSEventResponse)), []string{"b", "c", "slow"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("failures = %v, want %v", got, want)
+	}
+	if len(client.sent) != 0 {
+		t.Errorf("%d messages sent to the DLQ, want none for timeouts", len(client.sent))
+	}
+}
@@ -0,0 +1,174 @@
+package rambda
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+const (
+	defaultFlushInterval     = 100 * time.Millisecond
+	defaultStreamContentType = "application/octet-stream"
+)
+
+// StreamWriter is the io.Writer passed to StreamHandler functions.
+// The status code and headers can be changed until the first Write or Flush,
+// which sends them to the client.
+type StreamWriter struct {
+	mu        sync.Mutex
+	status    int
+	headers   map[string]string
+	buf       *bufio.Writer
+	committed bool
+
+	commitOnce sync.Once
+	commitCh   chan struct{}
+}
+
+func newStreamWriter(w io.Writer, o *options) *StreamWriter {
+	return &StreamWriter{
+		status:   http.StatusOK,
+		headers:  map[string]string{"Content-Type": o.contentType},
+		buf:      bufio.NewWriter(w),
+		commitCh: make(chan struct{}),
+	}
+}
+
+// SetStatus sets the HTTP status code. It has no effect after the first Write.
+func (w *StreamWriter) SetStatus(code int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.committed {
+		w.status = code
+	}
+}
+
+// SetHeader sets a response header. It has no effect after the first Write.
+func (w *StreamWriter) SetHeader(key, value string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.committed {
+		w.headers[key] = value
+	}
+}
+
+// Write buffers p; buffered data is sent periodically and when the buffer fills up.
+func (w *StreamWriter) Write(p []byte) (int, error) {
+	w.commit()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+// Flush sends buffered data to the client immediately.
+func (w *StreamWriter) Flush() error {
+	w.commit()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Flush()
+}
+
+func (w *StreamWriter) commit() {
+	w.commitOnce.Do(func() {
+		w.mu.Lock()
+		w.committed = true
+		w.mu.Unlock()
+		close(w.commitCh)
+	})
+}
+
+func (w *StreamWriter) response(body io.Reader) *events.LambdaFunctionURLStreamingResponse {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return &events.LambdaFunctionURLStreamingResponse{
+		StatusCode: w.status,
+		Headers:    w.headers,
+		Body:       body,
+	}
+}
+
+// finish sends what is still buffered and ends the stream, with err if fn failed.
+func (w *StreamWriter) finish(pw *io.PipeWriter, err error) {
+	if ferr := w.Flush(); err == nil {
+		err = ferr
+	}
+	pw.CloseWithError(err)
+}
+
+// StreamHandler adapts fn into a Function URL handler with RESPONSE_STREAM invoke mode,
+// letting responses exceed the 6MB limit of buffered invocations.
+//
+// The event is decoded into In and checked with Validate as in Typed. The response starts
+// with the first Write, or when fn returns; an error returned before that fails the
+// invocation, while a later one aborts the stream. Output is flushed every interval set
+// with WithFlushInterval and its default Content-Type is set with WithContentType.
+func StreamHandler[In any](fn func(ctx context.Context, event In, w io.Writer) error, opts ...Option) func(context.Context, json.RawMessage) (*events.LambdaFunctionURLStreamingResponse, error) {
+	o := newOptions(opts)
+	return func(ctx context.Context, event json.RawMessage) (*events.LambdaFunctionURLStreamingResponse, error) {
+		var in In
+		if err := unmarshal(event, &in); err != nil {
+			return nil, newDecodeError(&in, event, err)
+		}
+		if err := Validate(in); err != nil {
+			return nil, err
+		}
+
+		pr, pw := io.Pipe()
+		w := newStreamWriter(pw, o)
+		done := make(chan error, 1)
+		go func() {
+			done <- safeCall(func() error { return fn(ctx, in, w) })
+		}()
+
+		select {
+		case err := <-done:
+			w.mu.Lock()
+			committed := w.committed
+			w.mu.Unlock()
+			// 一度も書き込まずに失敗した場合だけ呼び出し自体をエラーにする
+			if err != nil && !committed {
+				pw.Close()
+				return nil, err
+			}
+			w.commit()
+			go w.finish(pw, err)
+			return w.response(pr), nil
+		case <-w.commitCh:
+		}
+
+		stop := make(chan struct{})
+		if o.flushInterval > 0 {
+			go func() {
+				ticker := time.NewTicker(o.flushInterval)
+				defer ticker.Stop()
+				for {
+					select {
+					case <-ticker.C:
+						_ = w.Flush()
+					case <-stop:
+						return
+					}
+				}
+			}()
+		}
+		go func() {
+			err := <-done
+			close(stop)
+			w.finish(pw, err)
+		}()
+		return w.response(pr), nil
+	}
+}
+
+// StartStream runs fn as a streaming Lambda handler for this process.
+// The function must be built with the lambda.norpc tag or run on a provided runtime.
+func StartStream[In any](fn func(ctx context.Context, event In, w io.Writer) error, opts ...Option) {
+	lambda.StartWithOptions(StreamHandler(fn, opts...), startOptions()...)
+}
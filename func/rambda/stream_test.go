@@ -0,0 +1,74 @@
+package rambda
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+type streamRequest struct {
+	Name string `json:"name" validate:"required"`
+}
+
+func TestStreamHandler(t *testing.T) {
+	release := make(chan struct{})
+	h := StreamHandler(func(ctx context.Context, req streamRequest, w io.Writer) error {
+		w.(*StreamWriter).SetStatus(201)
+		w.(*StreamWriter).SetHeader("Content-Type", "text/plain")
+		io.WriteString(w, "hello ")
+		<-release
+		_, err := io.WriteString(w, req.Name)
+		return err
+	}, WithFlushInterval(time.Millisecond))
+
+	res, err := h(context.Background(), json.RawMessage(`{"name":"gopher"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != 201 || res.Headers["Content-Type"] != "text/plain" {
+		t.Errorf("prelude = %d %v", res.StatusCode, res.Headers)
+	}
+
+	// 定期フラッシュにより、ハンドラの完了前に最初のチャンクが届くこと
+	first := make([]byte, len("hello "))
+	if _, err := io.ReadFull(res.Body, first); err != nil || string(first) != "hello " {
+		t.Fatalf("first chunk = %q, %v", first, err)
+	}
+	close(release)
+	rest, err := io.ReadAll(res.Body)
+	if err != nil || string(rest) != "gopher" {
+		t.Errorf("rest = %q, %v", rest, err)
+	}
+}
+
+func TestStreamHandlerErrors(t *testing.T) {
+	h := StreamHandler(func(ctx context.Context, req streamRequest, w io.Writer) error {
+		if req.Name == "early" {
+			return errors.New("failed before writing")
+		}
+		io.WriteString(w, "partial")
+		return errors.New("failed mid-stream")
+	})
+
+	if _, err := h(context.Background(), json.RawMessage(`{}`)); err == nil {
+		t.Error("validation error should fail the invocation")
+	}
+	if _, err := h(context.Background(), json.RawMessage(`{"name":"early"}`)); err == nil {
+		t.Error("error before the first write should fail the invocation")
+	}
+
+	res, err := h(context.Background(), json.RawMessage(`{"name":"late"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Headers["Content-Type"] != defaultStreamContentType {
+		t.Errorf("Content-Type = %q", res.Headers["Content-Type"])
+	}
+	body, err := io.ReadAll(res.Body)
+	if string(body) != "partial" || err == nil || err.Error() != "failed mid-stream" {
+		t.Errorf("body = %q, err = %v; want the partial body and the stream error", body, err)
+	}
+}
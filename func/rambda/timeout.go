@@ -0,0 +1,80 @@
+package rambda
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"time"
+)
+
+// TimeoutError is returned by TimeoutMiddleware when the handler runs past its budget.
+type TimeoutError struct {
+	// Budget is the time the handler was allowed to run.
+	Budget time.Duration
+	// Elapsed is the time spent before the invocation was abandoned.
+	Elapsed time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("rambda: handler timed out after %s of %s budget", e.Elapsed, e.Budget)
+}
+
+// Unwrap lets errors.Is(err, context.DeadlineExceeded) match a TimeoutError.
+func (e *TimeoutError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// TimeoutMiddleware gives the inner handler a context that expires buffer before
+// the invocation deadline, leaving time to flush telemetry before Lambda stops the container.
+// Invocations without a deadline are passed through unchanged.
+//
+// The handler runs on its own goroutine. A panic there is re-raised on the caller's
+// goroutine as a *PanicError holding the original stack, which RecoverMiddleware keeps.
+func TimeoutMiddleware(buffer time.Duration) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, event json.RawMessage) (any, error) {
+			deadline, ok := ctx.Deadline()
+			if !ok {
+				return next(ctx, event)
+			}
+			start := time.Now()
+			deadline = deadline.Add(-buffer)
+			budget := deadline.Sub(start)
+
+			ctx, cancel := context.WithDeadline(ctx, deadline)
+			defer cancel()
+
+			type result struct {
+				res any
+				err error
+			}
+			done := make(chan result, 1)
+			panicked := make(chan *PanicError, 1)
+			go func() {
+				defer func() {
+					if v := recover(); v != nil {
+						// スタックは panic したgoroutineでしか取れないのでここで記録する
+						panicked <- &PanicError{Value: v, Stack: debug.Stack()}
+					}
+				}()
+				res, err := next(ctx, event)
+				done <- result{res, err}
+			}()
+
+			select {
+			case r := <-done:
+				if r.err != nil && errors.Is(r.err, context.DeadlineExceeded) && ctx.Err() != nil {
+					return nil, &TimeoutError{Budget: budget, Elapsed: time.Since(start)}
+				}
+				return r.res, r.err
+			case pe := <-panicked:
+				// 外側のRecoverMiddlewareで拾えるように呼び出し元のgoroutineで投げ直す
+				panic(pe)
+			case <-ctx.Done():
+				return nil, &TimeoutError{Budget: budget, Elapsed: time.Since(start)}
+			}
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package rambda
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimeoutMiddleware(t *testing.T) {
+	slow := func(ctx context.Context, event json.RawMessage) (any, error) {
+		select {
+		case <-time.After(time.Second):
+			return "done", nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	h := Chain(slow, TimeoutMiddleware(150*time.Millisecond))
+	start := time.Now()
+	_, err := h(ctx, nil)
+
+	var te *TimeoutError
+	if !errors.As(err, &te) {
+		t.Fatalf("error = %v, want *TimeoutError", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Error("TimeoutError should match context.DeadlineExceeded")
+	}
+	if te.Budget <= 0 || te.Budget > 50*time.Millisecond {
+		t.Errorf("Budget = %s, want about 50ms", te.Budget)
+	}
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Errorf("returned after %s, expected to stop before the buffer", elapsed)
+	}
+}
+
+func TestTimeoutMiddlewarePassThrough(t *testing.T) {
+	h := Chain(func(ctx context.Context, event json.RawMessage) (any, error) {
+		if _, ok := ctx.Deadline(); ok {
+			t.Error("unexpected deadline")
+		}
+		return "ok", nil
+	}, TimeoutMiddleware(time.Second))
+
+	res, err := h(context.Background(), nil)
+	if err != nil || res != "ok" {
+		t.Errorf("got (%v, %v), want (ok, nil)", res, err)
+	}
+}
+
+func TestTimeoutMiddlewarePropagatesPanic(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	h := Chain(func(ctx context.Context, event json.RawMessage) (any, error) {
+		panic("boom")
+	}, RecoverMiddleware(), TimeoutMiddleware(10*time.Millisecond))
+
+	_, err := h(ctx, nil)
+	var pe *PanicError
+	if !errors.As(err, &pe) || pe.Value != "boom" {
+		t.Errorf("error = %v, want PanicError(boom)", err)
+	}
+}
+
+func timeoutDeepPanic() {
+	panic("deep")
+}
+
+func TestTimeoutMiddlewarePanicStack(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	h := Chain(func(ctx context.Context, event json.RawMessage) (any, error) {
+		timeoutDeepPanic()
+		return nil, nil
+	}, RecoverMiddleware(), TimeoutMiddleware(10*time.Millisecond))
+
+	_, err := h(ctx, nil)
+	var pe *PanicError
+	if !errors.As(err, &pe) || pe.Value != "deep" {
+		t.Fatalf("error = %v, want PanicError(deep)", err)
+	}
+	if !strings.Contains(string(pe.Stack), "timeoutDeepPanic") {
+		t.Errorf("stack does not contain the panicking frame:\n%s", pe.Stack)
+	}
+}
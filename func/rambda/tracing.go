@@ -0,0 +1,63 @@
+package rambda
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/aws/aws-xray-sdk-go/xray"
+)
+
+// TracingMiddleware wraps each invocation in an X-Ray subsegment called name, or the
+// function name when name is empty.
+//
+// The subsegment is linked to the trace in the invocation context or, failing that,
+// the _X_AMZN_TRACE_ID environment variable. A returned error or a panic marks it as a
+// fault and sets the "error" annotation. Without a trace header, or with
+// AWS_XRAY_SDK_DISABLED=true, the middleware does nothing.
+func TracingMiddleware(name string) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, event json.RawMessage) (res any, err error) {
+			if xray.SdkDisabled() {
+				return next(ctx, event)
+			}
+			if xray.GetSegment(ctx) == nil && ctx.Value(xray.LambdaTraceHeaderKey) == nil {
+				traceID := os.Getenv("_X_AMZN_TRACE_ID")
+				if traceID == "" {
+					return next(ctx, event)
+				}
+				ctx = context.WithValue(ctx, xray.LambdaTraceHeaderKey, traceID)
+			}
+
+			segName := name
+			if segName == "" {
+				lc, _ := FromContext(ctx)
+				segName = lc.FunctionName()
+			}
+			if segName == "" {
+				segName = "handler"
+			}
+			ctx, seg := xray.BeginSubsegment(ctx, segName)
+			if seg == nil {
+				return next(ctx, event)
+			}
+
+			defer func() {
+				if v := recover(); v != nil {
+					closeSegment(seg, fmt.Errorf("panic: %v", v))
+					panic(v)
+				}
+				closeSegment(seg, err)
+			}()
+			return next(ctx, event)
+		}
+	}
+}
+
+func closeSegment(seg *xray.Segment, err error) {
+	if err != nil {
+		_ = seg.AddAnnotation("error", true)
+	}
+	seg.Close(err)
+}
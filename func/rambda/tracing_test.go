@@ -0,0 +1,54 @@
+package rambda
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-xray-sdk-go/xray"
+)
+
+// Sampled=0 で送信を抑止しつつサブセグメントの生成だけ確認する
+const testTraceHeader = "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=0"
+
+func TestTracingMiddlewareNoTraceHeader(t *testing.T) {
+	t.Setenv("_X_AMZN_TRACE_ID", "")
+
+	var seg *xray.Segment
+	h := Chain(func(ctx context.Context, event json.RawMessage) (any, error) {
+		seg = xray.GetSegment(ctx)
+		return "ok", nil
+	}, TracingMiddleware("orders"))
+
+	res, err := h(context.Background(), json.RawMessage(`{}`))
+	if err != nil || res != "ok" {
+		t.Fatalf("got %v, %v", res, err)
+	}
+	if seg != nil {
+		t.Error("no subsegment should be opened without a trace header")
+	}
+}
+
+func TestTracingMiddlewareSubsegment(t *testing.T) {
+	t.Setenv("_X_AMZN_TRACE_ID", testTraceHeader)
+
+	var seg *xray.Segment
+	h := Chain(func(ctx context.Context, event json.RawMessage) (any, error) {
+		seg = xray.GetSegment(ctx)
+		return nil, errors.New("failed")
+	}, TracingMiddleware("orders"))
+
+	if _, err := h(context.Background(), json.RawMessage(`{}`)); err == nil {
+		t.Fatal("error should be passed through")
+	}
+	if seg == nil {
+		t.Fatal("handler should run inside a subsegment")
+	}
+	if seg.Name != "orders" || seg.TraceID != "1-5759e988-bd862e3fe1be46a994272793" {
+		t.Errorf("subsegment = %q in trace %q", seg.Name, seg.TraceID)
+	}
+	if !seg.Fault || seg.InProgress {
+		t.Errorf("fault = %v, in progress = %v; want a closed faulted subsegment", seg.Fault, seg.InProgress)
+	}
+}
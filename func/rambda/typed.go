@@ -0,0 +1,69 @@
+package rambda
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+// maxSnippetLen bounds how much of an undecodable payload is copied into a DecodeError.
+const maxSnippetLen = 128
+
+// DecodeError is returned when the event payload cannot be unmarshaled into the handler's input type.
+type DecodeError struct {
+	// Type is the Go type the payload was decoded into.
+	Type string
+	// Snippet is the beginning of the offending payload.
+	Snippet string
+	Err     error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("rambda: cannot decode event into %s: %v: %s", e.Type, e.Err, e.Snippet)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+func newDecodeError(target any, payload []byte, err error) *DecodeError {
+	snippet := string(payload)
+	if len(payload) > maxSnippetLen {
+		snippet = string(payload[:maxSnippetLen]) + "..."
+	}
+	return &DecodeError{
+		Type:    reflect.TypeOf(target).Elem().String(),
+		Snippet: snippet,
+		Err:     err,
+	}
+}
+
+// Typed adapts a strongly typed function into a Handler.
+// The payload is unmarshaled into In and checked with Validate before fn is called.
+// When In is an API Gateway request type, a base64 encoded body is decoded first, and a
+// body that is not valid base64 results in a 400 response from DefaultErrorMapper.
+func Typed[In any, Out any](fn func(context.Context, In) (Out, error)) Handler {
+	return func(ctx context.Context, event json.RawMessage) (any, error) {
+		var in In
+		if err := unmarshal(event, &in); err != nil {
+			return nil, newDecodeError(&in, event, err)
+		}
+		if err := decodeEventBody(&in); err != nil {
+			// API Gateway に 502 を返さないよう、APIHandler と同じく 400 の応答にする
+			return DefaultErrorMapper.MapError(err), nil
+		}
+		if err := Validate(in); err != nil {
+			return nil, err
+		}
+		return fn(ctx, in)
+	}
+}
+
+// Start runs fn as the Lambda handler for this process.
+// Results are returned as is unless an envelope is set with SetEnvelope.
+func Start[In any, Out any](fn func(context.Context, In) (Out, error)) {
+	lambda.StartWithOptions(Typed(fn), startOptions()...)
+}
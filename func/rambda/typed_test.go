@@ -0,0 +1,51 @@
+package rambda
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type greetRequest struct {
+	Name string `json:"name"`
+}
+
+type greetResponse struct {
+	Message string `json:"message"`
+}
+
+func greet(ctx context.Context, req greetRequest) (greetResponse, error) {
+	return greetResponse{Message: "Hello, " + req.Name}, nil
+}
+
+func TestTyped(t *testing.T) {
+	h := Typed(greet)
+
+	res, err := h(context.Background(), json.RawMessage(`{"name":"rambda"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != (greetResponse{Message: "Hello, rambda"}) {
+		t.Errorf("res = %v", res)
+	}
+}
+
+func TestTypedDecodeError(t *testing.T) {
+	h := Typed(greet)
+
+	payload := `{"name":` + strings.Repeat("1", 200) + `}`
+	_, err := h(context.Background(), json.RawMessage(payload))
+
+	var de *DecodeError
+	if !errors.As(err, &de) {
+		t.Fatalf("error = %v, want *DecodeError", err)
+	}
+	if de.Type != "rambda.greetRequest" {
+		t.Errorf("Type = %q", de.Type)
+	}
+	if len(de.Snippet) != maxSnippetLen+len("...") || !strings.HasPrefix(payload, strings.TrimSuffix(de.Snippet, "...")) {
+		t.Errorf("Snippet = %q", de.Snippet)
+	}
+}
@@ -0,0 +1,196 @@
+package rambda
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// FieldError describes one field that failed validation.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// ValidationError lists every field of an event that failed validation.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		msgs[i] = f.Field + ": " + f.Message
+	}
+	return "rambda: validation failed: " + strings.Join(msgs, "; ")
+}
+
+// MarshalJSON renders the error as {"error": ..., "fields": [...]}.
+func (e *ValidationError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Error  string       `json:"error"`
+		Fields []FieldError `json:"fields"`
+	}{
+		Error:  "validation failed",
+		Fields: e.Fields,
+	})
+}
+
+// APIResponse renders the error as a 400 response for API Gateway callers.
+func (e *ValidationError) APIResponse() APIResponse {
+	return JSON(http.StatusBadRequest, e)
+}
+
+// Validate checks v against its `validate` struct tags.
+// Supported rules are required, min=N, max=N and email; they may be combined with commas.
+// For strings, slices and maps min and max bound the length, for numbers the value.
+// Every failing field is reported in the returned *ValidationError.
+func Validate(v any) error {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var fields []FieldError
+	validateStruct(rv, "", &fields)
+	if len(fields) > 0 {
+		return &ValidationError{Fields: fields}
+	}
+	return nil
+}
+
+func validateStruct(rv reflect.Value, prefix string, fields *[]FieldError) {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name := prefix + jsonFieldName(sf)
+		fv := rv.Field(i)
+
+		for _, rule := range parseRules(sf.Tag.Get("validate")) {
+			if msg, ok := checkRule(fv, rule); !ok {
+				*fields = append(*fields, FieldError{Field: name, Rule: rule.name, Message: msg})
+			}
+		}
+
+		// ネストした構造体も検証する
+		for fv.Kind() == reflect.Pointer && !fv.IsNil() {
+			fv = fv.Elem()
+		}
+		if fv.Kind() == reflect.Struct {
+			validateStruct(fv, name+".", fields)
+		}
+	}
+}
+
+// jsonFieldName returns the name a struct field has in JSON, or in the request for fields bound by BindRequest.
+func jsonFieldName(sf reflect.StructField) string {
+	name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+	if name != "" && name != "-" {
+		return name
+	}
+	for _, key := range []string{"path", "query", "header"} {
+		if name := sf.Tag.Get(key); name != "" {
+			return name
+		}
+	}
+	return sf.Name
+}
+
+type rule struct {
+	name  string
+	param string
+}
+
+func parseRules(tag string) []rule {
+	if tag == "" {
+		return nil
+	}
+	var rules []rule
+	for _, part := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(strings.TrimSpace(part), "=")
+		if name != "" {
+			rules = append(rules, rule{name: name, param: param})
+		}
+	}
+	return rules
+}
+
+func checkRule(fv reflect.Value, r rule) (string, bool) {
+	switch r.name {
+	case "required":
+		if fv.IsZero() {
+			return "is required", false
+		}
+	case "min", "max":
+		limit, err := strconv.ParseFloat(r.param, 64)
+		if err != nil {
+			return fmt.Sprintf("has invalid %s rule %q", r.name, r.param), false
+		}
+		n, isLen, ok := measure(fv)
+		if !ok {
+			return "", true
+		}
+		if r.name == "min" && n < limit {
+			if isLen {
+				return fmt.Sprintf("must have length at least %s", r.param), false
+			}
+			return fmt.Sprintf("must be at least %s", r.param), false
+		}
+		if r.name == "max" && n > limit {
+			if isLen {
+				return fmt.Sprintf("must have length at most %s", r.param), false
+			}
+			return fmt.Sprintf("must be at most %s", r.param), false
+		}
+	case "email":
+		for fv.Kind() == reflect.Pointer && !fv.IsNil() {
+			fv = fv.Elem()
+		}
+		if fv.Kind() != reflect.String || fv.String() == "" {
+			return "", true
+		}
+		if addr, err := mail.ParseAddress(fv.String()); err != nil || addr.Address != fv.String() {
+			return "must be a valid email address", false
+		}
+	default:
+		return fmt.Sprintf("has unknown rule %q", r.name), false
+	}
+	return "", true
+}
+
+// measure returns the length or numeric value min and max compare against.
+func measure(fv reflect.Value) (n float64, isLen bool, ok bool) {
+	for fv.Kind() == reflect.Pointer {
+		if fv.IsNil() {
+			return 0, false, false
+		}
+		fv = fv.Elem()
+	}
+	switch fv.Kind() {
+	case reflect.String:
+		return float64(len([]rune(fv.String()))), true, true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(fv.Len()), true, true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(fv.Int()), false, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(fv.Uint()), false, true
+	case reflect.Float32, reflect.Float64:
+		return fv.Float(), false, true
+	}
+	return 0, false, false
+}
@@ -0,0 +1,80 @@
+package rambda
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+type signupRequest struct {
+	Name    string   `json:"name" validate:"required"`
+	Email   string   `json:"email" validate:"required,email"`
+	Age     int      `json:"age" validate:"min=18,max=130"`
+	Tags    []string `json:"tags" validate:"min=1"`
+	Address *struct {
+		City string `json:"city" validate:"required"`
+	} `json:"address"`
+}
+
+func TestValidate(t *testing.T) {
+	req := signupRequest{Email: "not-an-email", Age: 12}
+	req.Address = &struct {
+		City string `json:"city" validate:"required"`
+	}{}
+
+	err := Validate(req)
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("error = %v, want *ValidationError", err)
+	}
+
+	var got []string
+	for _, f := range ve.Fields {
+		got = append(got, f.Field+"/"+f.Rule)
+	}
+	want := []string{"name/required", "email/email", "age/min", "tags/min", "address.city/required"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("fields = %v, want %v", got, want)
+	}
+}
+
+func TestValidatePasses(t *testing.T) {
+	req := signupRequest{Name: "a", Email: "a@example.com", Age: 20, Tags: []string{"x"}}
+	if err := Validate(&req); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+	if err := Validate("not a struct"); err != nil {
+		t.Errorf("Validate(string) = %v", err)
+	}
+}
+
+func TestValidationErrorAPIResponse(t *testing.T) {
+	ve := &ValidationError{Fields: []FieldError{{Field: "name", Rule: "required", Message: "is required"}}}
+	res := ve.APIResponse()
+	if res.StatusCode != http.StatusBadRequest {
+		t.Errorf("StatusCode = %d", res.StatusCode)
+	}
+	want := `{"error":"validation failed","fields":[{"field":"name","rule":"required","message":"is required"}]}`
+	if res.Body != want {
+		t.Errorf("Body = %s, want %s", res.Body, want)
+	}
+}
+
+func TestTypedValidates(t *testing.T) {
+	called := false
+	h := Typed(func(ctx context.Context, req signupRequest) (string, error) {
+		called = true
+		return "ok", nil
+	})
+	_, err := h(context.Background(), json.RawMessage(`{"name":"a"}`))
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("error = %v, want *ValidationError", err)
+	}
+	if called {
+		t.Error("handler should not run when validation fails")
+	}
+}
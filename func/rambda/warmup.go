@@ -0,0 +1,33 @@
+package rambda
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// IsWarmupEvent is the default WarmupMiddleware predicate. It matches {"warmup": true}.
+func IsWarmupEvent(event json.RawMessage) bool {
+	var probe struct {
+		Warmup bool `json:"warmup"`
+	}
+	return unmarshal(event, &probe) == nil && probe.Warmup
+}
+
+// WarmupMiddleware answers keep-warm pings with {"warmed": true} without calling the inner handler.
+// Events are pings when predicate returns true; a nil predicate uses IsWarmupEvent.
+//
+// Pings still count as invocations for IsColdStart.
+func WarmupMiddleware(predicate func(json.RawMessage) bool) Middleware {
+	if predicate == nil {
+		predicate = IsWarmupEvent
+	}
+	return func(next Handler) Handler {
+		return func(ctx context.Context, event json.RawMessage) (any, error) {
+			if !predicate(event) {
+				return next(ctx, event)
+			}
+			recordInvocation(ctx)
+			return map[string]bool{"warmed": true}, nil
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package rambda
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestWarmupMiddleware(t *testing.T) {
+	resetColdStart()
+	t.Cleanup(resetColdStart)
+
+	calls := 0
+	inner := func(ctx context.Context, event json.RawMessage) (any, error) {
+		calls++
+		return "real", nil
+	}
+	h := Chain(inner, WarmupMiddleware(nil))
+
+	out, err := h.Invoke(context.Background(), []byte(`{"warmup":true}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != `{"warmed":true}` || calls != 0 {
+		t.Errorf("ping = %s with %d inner calls, want {\"warmed\":true} and none", out, calls)
+	}
+
+	if res, _ := h(context.Background(), json.RawMessage(`{"warmup":false}`)); res != "real" || calls != 1 {
+		t.Errorf("regular event = %v, want the inner handler result", res)
+	}
+
+	h = Chain(inner, WarmupMiddleware(func(event json.RawMessage) bool {
+		return bytes.Contains(event, []byte(`"source":"serverless-plugin-warmup"`))
+	}))
+	if _, err := h(context.Background(), json.RawMessage(`{"source":"serverless-plugin-warmup"}`)); err != nil || calls != 1 {
+		t.Errorf("custom predicate should short-circuit, inner calls = %d", calls)
+	}
+	if IsColdStart() {
+		t.Error("pings should count toward cold start initialization")
+	}
+}